@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -23,16 +24,47 @@ import (
 // Session represents a Netconf Session
 type Session interface {
 	// Execute executes an RPC request on the server and returns the reply.
+	// If the reply contains any rpc-error with error severity, the reply is returned along with a
+	// *common.RPCErrors describing every rpc-error.
 	Execute(req common.Request) (*common.RPCReply, error)
 
 	// ExecuteAsync submits an RPC request for execution on the server, arranging for the
 	// reply to be sent to the supplied channel.
 	ExecuteAsync(req common.Request, rchan chan *common.RPCReply) (err error)
 
+	// ExecuteContext executes an RPC request on the server and returns the reply.
+	// If the context is cancelled, or its deadline expires, before the reply is received, the request is
+	// abandoned and the context error is returned; any reply that subsequently arrives is discarded.
+	ExecuteContext(ctx context.Context, req common.Request) (*common.RPCReply, error)
+
+	// ExecuteAsyncContext submits an RPC request for execution on the server, arranging for the
+	// reply to be sent to the supplied channel.
+	// If the context is cancelled, or its deadline expires, before the reply is received, the request is
+	// abandoned and no reply will be sent to the channel, so callers should also wait on ctx.Done().
+	ExecuteAsyncContext(ctx context.Context, req common.Request, rchan chan *common.RPCReply) (err error)
+
+	// ExecuteStream executes an RPC request on the server and returns a stream that delivers the content
+	// of the reply as it is received, without buffering the complete reply.
+	// The caller must Close the stream when finished with it; no further messages will be processed by the
+	// session until it has done so.
+	ExecuteStream(ctx context.Context, req common.Request) (*ReplyStream, error)
+
 	// Subscribe issues an RPC request and returns the reply. If successful, notifications will
-	// be sent to the supplied channel.
+	// be sent to the supplied channel, in place of any channel supplied previously; if not, notifications
+	// continue to be sent to the previous channel.
 	Subscribe(req common.Request, nchan chan *common.Notification) (reply *common.RPCReply, err error)
 
+	// AddNotificationSink registers a sink that will receive the notifications that match its configuration,
+	// in addition to any subscription channel. Notifications are delivered to every matching sink.
+	// The sink's channel is closed when the sink is removed, or the session is closed.
+	AddNotificationSink(cfg *SinkConfig) *NotificationSink
+
+	// RemoveNotificationSink removes the sink, closing its channel.
+	RemoveNotificationSink(sink *NotificationSink)
+
+	// NotificationDrops delivers the number of notifications dropped by each registered sink, keyed by sink name.
+	NotificationDrops() map[string]uint64
+
 	// Close closes the session and releases any associated resources.
 	// The channel will be automatically closed if the underlying network connection is closed, for
 	// example if the remote server disconnects.
@@ -57,8 +89,12 @@ type sesImpl struct {
 	pool []chan *common.RPCReply
 
 	hellochan chan bool
-	responseq []chan *common.RPCReply
+	closedch  chan struct{}
+	responseq []*pendingReply
+	pending   map[string]*pendingReply
 	subchan   chan *common.Notification
+	// numberedReplies is set once a reply with a message id has been received.
+	numberedReplies bool
 
 	hello   *common.HelloMessage
 	reqLock sync.Mutex
@@ -67,9 +103,38 @@ type sesImpl struct {
 
 	notificationDropCount uint64
 
+	sinkLock  sync.Mutex
+	sinks     []*NotificationSink
+	sinkCount int
+	// sinkList holds a copy of sinks, which can be read without holding sinkLock.
+	sinkList    atomic.Value
+	sinksClosed bool
+	closing     chan struct{}
+	closeOnce   sync.Once
+
 	target string
 }
 
+// pendingReply associates an outstanding request with the channel that will receive its reply.
+type pendingReply struct {
+	// id is the message-id of the request.
+	id string
+	ch chan *common.RPCReply
+	// sch is defined instead of ch if the reply is to be streamed to the requester.
+	sch chan *ReplyStream
+	// done is closed when the entry is removed from the response queue.
+	done chan struct{}
+	// abandoned indicates that the requester is no longer waiting for the reply.
+	abandoned bool
+	// malformed indicates that the request was sent in a form that the server will reject, so its reply may
+	// not carry the message id.
+	malformed bool
+}
+
+// maxAbandonedReplies defines the number of abandoned requests that are kept on the response queue, to absorb
+// replies without a message id.
+const maxAbandonedReplies = 16
+
 // NewSession creates a new Netconf session, using the supplied Transport.
 func NewSession(ctx context.Context, t Transport, cfg *Config) (Session, error) {
 	si := &sesImpl{
@@ -81,6 +146,9 @@ func NewSession(ctx context.Context, t Transport, cfg *Config) (Session, error)
 		trace:  ContextClientTrace(ctx),
 
 		hellochan: make(chan bool),
+		closedch:  make(chan struct{}),
+		closing:   make(chan struct{}),
+		pending:   make(map[string]*pendingReply),
 	}
 
 	// Send hello
@@ -111,6 +179,10 @@ func (si *sesImpl) clientCapabilities() []string {
 }
 
 func (si *sesImpl) Execute(req common.Request) (reply *common.RPCReply, err error) {
+	return si.ExecuteContext(context.Background(), req)
+}
+
+func (si *sesImpl) ExecuteContext(ctx context.Context, req common.Request) (reply *common.RPCReply, err error) {
 	si.trace.ExecuteStart(req, false)
 
 	defer func(begin time.Time) {
@@ -122,28 +194,85 @@ func (si *sesImpl) Execute(req common.Request) (reply *common.RPCReply, err erro
 	defer si.relChan(rchan)
 
 	// Submit the request
-	err = si.execute(req, rchan)
-	if err != nil {
+	pr := &pendingReply{ch: rchan}
+	if err = si.execute(ctx, req, pr); err != nil {
 		return nil, err
 	}
 
-	// Wait for the response.
-	reply = <-rchan
+	// Wait for the response, or for the context to be done.
+	select {
+	case reply = <-rchan:
+	case <-ctx.Done():
+		if si.abandon(pr) {
+			return nil, ctx.Err()
+		}
+		// The reply has already been dispatched to the response channel, so collect it.
+		reply = <-rchan
+	}
 
 	err = mapError(reply)
 	return reply, err
 }
 
 func (si *sesImpl) ExecuteAsync(req common.Request, rchan chan *common.RPCReply) (err error) {
+	return si.ExecuteAsyncContext(context.Background(), req, rchan)
+}
+
+func (si *sesImpl) ExecuteAsyncContext(ctx context.Context, req common.Request, rchan chan *common.RPCReply) (err error) {
 	si.trace.ExecuteStart(req, true)
 	defer func(begin time.Time) {
 		si.trace.ExecuteDone(req, true, nil, err, time.Since(begin))
 	}(time.Now())
 
-	return si.execute(req, rchan)
+	pr := &pendingReply{ch: rchan}
+	if err = si.execute(ctx, req, pr); err != nil || ctx.Done() == nil {
+		return
+	}
+
+	// Abandon the request if the context is done before the reply is dispatched.
+	go func() {
+		select {
+		case <-ctx.Done():
+			si.abandon(pr)
+		case <-pr.done:
+		}
+	}()
+	return
+}
+
+func (si *sesImpl) ExecuteStream(ctx context.Context, req common.Request) (rs *ReplyStream, err error) {
+	si.trace.ExecuteStart(req, false)
+	defer func(begin time.Time) {
+		si.trace.ExecuteDone(req, false, nil, err, time.Since(begin))
+	}(time.Now())
+
+	pr := &pendingReply{sch: make(chan *ReplyStream, 1)}
+	if err = si.execute(ctx, req, pr); err != nil {
+		return nil, err
+	}
+
+	// Wait for the reply to start arriving, or for the context to be done.
+	select {
+	case rs = <-pr.sch:
+	case <-ctx.Done():
+		if si.abandon(pr) {
+			return nil, ctx.Err()
+		}
+		// The stream has already been dispatched, so collect it.
+		rs = <-pr.sch
+	}
+
+	if rs == nil {
+		return nil, io.ErrUnexpectedEOF
+	}
+	return rs, nil
 }
 
-func (si *sesImpl) execute(req common.Request, rchan chan *common.RPCReply) (err error) {
+func (si *sesImpl) execute(ctx context.Context, req common.Request, pr *pendingReply) (err error) {
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
 	// Build the request to be submitted.
 	msg := &common.RPCMessage{MessageID: uuid.New().String(), Union: common.GetUnion(req)}
 
@@ -151,22 +280,140 @@ func (si *sesImpl) execute(req common.Request, rchan chan *common.RPCReply) (err
 	si.reqLock.Lock()
 	defer si.reqLock.Unlock()
 
-	// Add the response channel to the response queue, but take it off if the request was not
+	// Register the response channel against the message id, but remove it if the request was not
 	// submitted successfully.
-	si.pushRespChan(rchan)
-	if err = si.enc.Encode(msg); err != nil {
-		si.popRespChan()
+	pr.id, pr.done = msg.MessageID, make(chan struct{})
+	si.pushRespChan(pr)
+	if err = si.encode(req, msg); err != nil {
+		var cerr *codec.ContentError
+		if errors.As(err, &cerr) {
+			// The request was sent in a form that the server will reject, so its reply, which may not carry
+			// the message id, is discarded.
+			si.rchLock.Lock()
+			pr.malformed = true
+			si.rchLock.Unlock()
+			si.abandon(pr)
+			return cerr.Err
+		}
+		si.popRespChan(pr.id)
 	}
 	return
 }
 
+// encode encodes the request message, streaming the content of the request if it defines a content reader.
+func (si *sesImpl) encode(req common.Request, msg *common.RPCMessage) error {
+	if sreq, ok := req.(common.StreamingRequest); ok {
+		if r := sreq.ContentReader(); r != nil {
+			return si.enc.EncodeStream(msg, common.StreamContentMarker, r)
+		}
+	}
+	return si.enc.Encode(msg)
+}
+
 func (si *sesImpl) Subscribe(req common.Request, nchan chan *common.Notification) (reply *common.RPCReply, err error) {
 	// Store the notification channel for the session.
-	si.subchan = nchan
-	return si.Execute(req)
+	si.sinkLock.Lock()
+	prev := si.subchan
+	if !si.sinksClosed {
+		si.subchan = nchan
+	}
+	si.sinkLock.Unlock()
+
+	if reply, err = si.Execute(req); err != nil {
+		// Reinstate the previous channel, so that a rejected request does not end an existing subscription.
+		// If the session has been closed meanwhile, the previous channel is closed, as it would have been
+		// had it not been replaced.
+		si.sinkLock.Lock()
+		switch {
+		case si.subchan == nchan:
+			si.subchan = prev
+		case si.sinksClosed && prev != nil:
+			close(prev)
+		}
+		si.sinkLock.Unlock()
+	}
+	return
+}
+
+func (si *sesImpl) AddNotificationSink(cfg *SinkConfig) *NotificationSink {
+	si.sinkLock.Lock()
+	defer si.sinkLock.Unlock()
+
+	si.sinkCount++
+	sinkCfg := *cfg
+	if sinkCfg.Name == "" {
+		sinkCfg.Name = fmt.Sprintf("sink-%d", si.sinkCount)
+	}
+	sink := newNotificationSink(&sinkCfg, si.trace)
+	if si.sinksClosed {
+		sink.close()
+		return sink
+	}
+	si.setSinks(append(si.sinks[:len(si.sinks):len(si.sinks)], sink))
+	return sink
+}
+
+func (si *sesImpl) RemoveNotificationSink(sink *NotificationSink) {
+	si.sinkLock.Lock()
+	defer si.sinkLock.Unlock()
+	for i, s := range si.sinks {
+		if s == sink {
+			sinks := make([]*NotificationSink, 0, len(si.sinks)-1)
+			si.setSinks(append(append(sinks, si.sinks[:i]...), si.sinks[i+1:]...))
+			sink.close()
+			return
+		}
+	}
+}
+
+func (si *sesImpl) NotificationDrops() map[string]uint64 {
+	sinks, _ := si.sinkList.Load().([]*NotificationSink)
+	drops := make(map[string]uint64, len(sinks))
+	for _, sink := range sinks {
+		drops[sink.Name()] = sink.Dropped()
+	}
+	return drops
+}
+
+// setSinks replaces the registered sinks; it must be called with sinkLock held.
+func (si *sesImpl) setSinks(sinks []*NotificationSink) {
+	si.sinks = sinks
+	si.sinkList.Store(sinks)
+}
+
+// closeSinks closes the subscription channel and the notification sinks.
+func (si *sesImpl) closeSinks() {
+	si.sinkLock.Lock()
+	defer si.sinkLock.Unlock()
+	if si.subchan != nil {
+		close(si.subchan)
+		si.subchan = nil
+	}
+	for _, sink := range si.sinks {
+		sink.close()
+	}
+	si.setSinks(nil)
+	si.sinksClosed = true
+}
+
+// done returns a channel that is closed when the underlying transport of the session has been closed.
+func (si *sesImpl) done() <-chan struct{} {
+	return si.closedch
+}
+
+// sessionDone returns the channel that is closed when the transport of s has been closed, or nil, which is never
+// ready, if s does not report it.
+func sessionDone(s Session) <-chan struct{} {
+	if d, ok := s.(interface{ done() <-chan struct{} }); ok {
+		return d.done()
+	}
+	return nil
 }
 
 func (si *sesImpl) Close() {
+	si.closeOnce.Do(func() {
+		close(si.closing)
+	})
 	err := si.t.Close()
 	if err != nil {
 		si.trace.Error("Session close failed", si.target, err)
@@ -250,31 +497,72 @@ func (si *sesImpl) handleHello(token xml.StartElement) (err error) {
 }
 
 func (si *sesImpl) handleRPCReply(token xml.StartElement) (err error) {
+	// Find the request that matches the message id of the reply and send the reply to its channel,
+	// unless the requester has given up waiting.
+	// Replies without a message id are matched to the oldest outstanding request.
+	pr := si.popRespChan(replyMessageID(token))
+	if pr != nil && pr.sch != nil && !pr.abandoned {
+		return si.streamRPCReply(token, pr.sch)
+	}
+
 	reply := common.RPCReply{}
 	if err = si.decodeElement(&reply, &token); err != nil {
+		if pr != nil && !pr.abandoned {
+			si.closeRespChan(pr)
+		}
 		return
 	}
 
-	// Pop the channel off the head of the queue and send the reply to it.
-	respch := si.popRespChan()
+	switch {
+	case pr == nil:
+		si.trace.Error(fmt.Sprintf("Discarding rpc-reply message-id:%s", reply.MessageID), si.target, ErrUnmatchedReply)
+		si.trace.ReplyDropped(&reply, ErrUnmatchedReply)
+		return
+	case pr.abandoned:
+		si.trace.ReplyDropped(&reply, ErrAbandonedReply)
+		return
+	}
 	go func(ch chan *common.RPCReply, r *common.RPCReply) {
 		ch <- r
-	}(respch, &reply)
+	}(pr.ch, &reply)
 	return
 }
 
+// streamRPCReply hands the content of the reply to the requester as a stream, and waits for the requester to
+// finish with it.
+func (si *sesImpl) streamRPCReply(token xml.StartElement, sch chan *ReplyStream) error {
+	rs := newReplyStream(si.dec, token)
+	sch <- rs
+	<-rs.closed
+	return rs.readErr
+}
+
+func replyMessageID(token xml.StartElement) string {
+	for _, attr := range token.Attr {
+		if attr.Name.Local == "message-id" {
+			return attr.Value
+		}
+	}
+	return ""
+}
+
 func (si *sesImpl) handleNotification(token xml.StartElement) (err error) {
 	result := &common.NotificationMessage{}
 	if err = si.decodeElement(&result, &token); err != nil {
 		return
 	}
 
-	// Send notification to subscription channel, if it's defined and not full.
-	if si.subchan != nil {
-		notification := buildNotification(result)
+	si.sinkLock.Lock()
+	if si.subchan == nil && len(si.sinks) == 0 {
+		si.sinkLock.Unlock()
+		return
+	}
 
-		si.trace.NotificationReceived(notification)
+	notification := si.buildNotification(&token, result)
+	si.trace.NotificationReceived(notification)
 
+	// Send notification to subscription channel, if it's defined and not full.
+	if si.subchan != nil {
 		select {
 		case si.subchan <- notification:
 		default:
@@ -282,14 +570,118 @@ func (si *sesImpl) handleNotification(token xml.StartElement) (err error) {
 			si.trace.NotificationDropped(notification)
 		}
 	}
+	si.sinkLock.Unlock()
+
+	// The sinks are delivered to without holding the lock, as delivery to a Block sink waits for its reader,
+	// which may itself be subscribing, or adding or removing sinks.
+	sinks, _ := si.sinkList.Load().([]*NotificationSink)
+	for _, sink := range sinks {
+		if sink.matches(notification) {
+			sink.deliver(notification, si.closing)
+		}
+	}
 	return
 }
 
-func buildNotification(nmsg *common.NotificationMessage) *common.Notification {
-	event := fmt.Sprintf(`<%s xmlns=%q>%s</%s>`,
-		nmsg.Event.XMLName.Local, nmsg.Event.XMLName.Space, nmsg.Event.Event, nmsg.Event.XMLName.Local)
-	notification := &common.Notification{XMLName: nmsg.Event.XMLName, EventTime: nmsg.EventTime, Event: event}
-	return notification
+func (si *sesImpl) buildNotification(start *xml.StartElement, nmsg *common.NotificationMessage) *common.Notification {
+	raw := strings.TrimSpace(nmsg.EventTime)
+	eventTime, err := parseEventTime(raw)
+	if err != nil {
+		si.trace.Error(fmt.Sprintf("Invalid notification eventTime:%q", raw), si.target, err)
+	}
+	return &common.Notification{XMLName: nmsg.Event.XMLName, EventTime: eventTime, RawEventTime: raw,
+		Event: eventXML(start, nmsg.Content)}
+}
+
+// eventTimeLayouts defines the layouts accepted for the event time of a notification: the date-time format
+// required by RFC 5277, followed by variants sent by some servers, with no time zone (taken to be UTC) or
+// with no colon in the zone offset.
+var eventTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999Z0700",
+}
+
+// parseEventTime parses the event time of a notification, returning the error of the RFC 5277 format if it
+// cannot be parsed with any of the accepted layouts.
+func parseEventTime(s string) (time.Time, error) {
+	t, err := time.Parse(eventTimeLayouts[0], s)
+	if err == nil {
+		return t, nil
+	}
+	for _, layout := range eventTimeLayouts[1:] {
+		if t, lerr := time.Parse(layout, s); lerr == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, err
+}
+
+// eventXML extracts the event element from the content of a notification message, as sent by the server.
+// Any namespace declarations of the notification element that are not redeclared by the event element are
+// added to it, so that the event can be decoded in isolation.
+func eventXML(start *xml.StartElement, content string) string {
+	d := xml.NewDecoder(strings.NewReader(content))
+	depth, begin := 0, int64(-1)
+	var event xml.StartElement
+	for {
+		offset := d.InputOffset()
+		token, err := d.RawToken()
+		if err != nil {
+			return ""
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			if depth == 0 && t.Name.Local != "eventTime" {
+				begin, event = offset, t
+			}
+			depth++
+		case xml.EndElement:
+			depth--
+			if depth == 0 && begin >= 0 {
+				return inheritNamespaces(content[begin:d.InputOffset()], &event, start.Attr)
+			}
+		}
+	}
+}
+
+func inheritNamespaces(event string, start *xml.StartElement, parentAttrs []xml.Attr) string {
+	sb := &strings.Builder{}
+	for _, attr := range parentAttrs {
+		if !isNamespaceDecl(attr.Name) || hasAttr(start.Attr, attr.Name) {
+			continue
+		}
+		name := attr.Name.Local
+		if attr.Name.Space != "" {
+			name = attr.Name.Space + ":" + name
+		}
+		sb.WriteString(" " + name + `="`)
+		_ = xml.EscapeText(sb, []byte(attr.Value))
+		sb.WriteByte('"')
+	}
+	if sb.Len() == 0 {
+		return event
+	}
+
+	// Insert the declarations after the (raw) element name.
+	at := len("<") + len(start.Name.Local)
+	if start.Name.Space != "" {
+		at += len(start.Name.Space) + len(":")
+	}
+	return event[:at] + sb.String() + event[at:]
+}
+
+func isNamespaceDecl(name xml.Name) bool {
+	return name.Space == "xmlns" || (name.Space == "" && name.Local == "xmlns")
+}
+
+func hasAttr(attrs []xml.Attr, name xml.Name) bool {
+	for _, attr := range attrs {
+		if attr.Name == name {
+			return true
+		}
+	}
+	return false
 }
 
 func (si *sesImpl) decodeElement(v interface{}, start *xml.StartElement) (err error) {
@@ -301,19 +693,28 @@ func (si *sesImpl) decodeElement(v interface{}, start *xml.StartElement) (err er
 
 func (si *sesImpl) closeChannels() {
 	close(si.hellochan)
-	if si.subchan != nil {
-		close(si.subchan)
-	}
+	close(si.closedch)
+	si.closeSinks()
 	si.closeAllResponseChannels()
 }
 
 func (si *sesImpl) closeAllResponseChannels() {
 	for {
-		if ch := si.popRespChan(); ch != nil {
-			close(ch)
-		} else {
+		pr := si.popRespChan("")
+		if pr == nil {
 			return
 		}
+		if !pr.abandoned {
+			si.closeRespChan(pr)
+		}
+	}
+}
+
+func (si *sesImpl) closeRespChan(pr *pendingReply) {
+	if pr.sch != nil {
+		close(pr.sch)
+	} else {
+		close(pr.ch)
 	}
 }
 
@@ -336,33 +737,105 @@ func (si *sesImpl) relChan(ch chan *common.RPCReply) {
 	si.pool = append(si.pool, ch)
 }
 
-func (si *sesImpl) pushRespChan(ch chan *common.RPCReply) {
+func (si *sesImpl) pushRespChan(pr *pendingReply) {
 	si.rchLock.Lock()
 	defer si.rchLock.Unlock()
-	si.responseq = append(si.responseq, ch)
+	si.responseq = append(si.responseq, pr)
+	si.pending[pr.id] = pr
 }
 
-func (si *sesImpl) popRespChan() (ch chan *common.RPCReply) {
+// popRespChan removes and returns the pending reply registered against the message id, or nil if there
+// is no such reply.
+// If the message id is empty, the oldest pending reply is removed.
+func (si *sesImpl) popRespChan(id string) (pr *pendingReply) {
 	si.rchLock.Lock()
 	defer si.rchLock.Unlock()
-	if len(si.responseq) > 0 {
-		si.responseq, ch = si.responseq[1:], si.responseq[0]
+
+	if id == "" {
+		if len(si.responseq) == 0 {
+			return nil
+		}
+		pr = si.responseq[0]
+	} else {
+		if !si.numberedReplies {
+			si.numberedReplies = true
+			si.trimAbandoned()
+		}
+		if pr = si.pending[id]; pr == nil {
+			return nil
+		}
 	}
-	return
+
+	si.removeRespChan(pr)
+	return pr
+}
+
+// removeRespChan removes a pending reply from the response queue; it must be called with rchLock held.
+func (si *sesImpl) removeRespChan(pr *pendingReply) {
+	delete(si.pending, pr.id)
+	for i, qpr := range si.responseq {
+		if qpr == pr {
+			si.responseq = append(si.responseq[:i], si.responseq[i+1:]...)
+			break
+		}
+	}
+	close(pr.done)
 }
 
+// abandon marks a pending reply as no longer required, returning false if the reply has already been
+// dispatched to the response channel.
+// The entry is removed from the pending replies, so any reply that arrives with its message id is unmatched.
+// Until the server is known to number its replies, the entry is left on the response queue, so that replies
+// without a message id are not mismatched.
+func (si *sesImpl) abandon(pr *pendingReply) bool {
+	si.rchLock.Lock()
+	defer si.rchLock.Unlock()
+	select {
+	case <-pr.done:
+		return false
+	default:
+	}
+	pr.abandoned = true
+	delete(si.pending, pr.id)
+	si.trimAbandoned()
+	return true
+}
+
+// trimAbandoned removes the abandoned entries from the response queue that are not needed to absorb replies without
+// a message id, keeping at most maxAbandonedReplies of the most recent; it must be called with rchLock held.
+func (si *sesImpl) trimAbandoned() {
+	var kept []*pendingReply
+	for _, pr := range append([]*pendingReply(nil), si.responseq...) {
+		switch {
+		case !pr.abandoned:
+		case si.numberedReplies && !pr.malformed:
+			si.removeRespChan(pr)
+		default:
+			kept = append(kept, pr)
+		}
+	}
+	for ; len(kept) > maxAbandonedReplies; kept = kept[1:] {
+		si.removeRespChan(kept[0])
+	}
+}
+
+var (
+	// ErrUnmatchedReply is reported when an rpc-reply is received that does not correspond to any
+	// outstanding request.
+	ErrUnmatchedReply = errors.New("rpc-reply does not match any outstanding request")
+
+	// ErrAbandonedReply is reported when an rpc-reply without a message id is received for a request whose
+	// requester is no longer waiting for the reply.
+	ErrAbandonedReply = errors.New("rpc-reply received for abandoned request")
+)
+
 // Map an RPC reply to an error, if the reply is either null or contains any RPC error.
-func mapError(r *common.RPCReply) (err error) {
+func mapError(r *common.RPCReply) error {
 	if r == nil {
-		err = io.ErrUnexpectedEOF
-	} else if r.Errors != nil {
-		for i := 0; i < len(r.Errors); i++ {
-			rpcErr := r.Errors[i]
-			if rpcErr.Severity == "error" {
-				err = &rpcErr
-				break
-			}
-		}
+		return io.ErrUnexpectedEOF
 	}
-	return
+	if errs := common.NewRPCErrors(r); errs != nil {
+		return errs
+	}
+	return nil
 }
@@ -3,6 +3,7 @@ package client
 import (
 	"context"
 	"encoding/xml"
+	"errors"
 	"fmt"
 	"sync"
 	"sync/atomic"
@@ -10,6 +11,7 @@ import (
 	"time"
 
 	"github.com/damianoneill/net/v2/netconf/common"
+	"github.com/damianoneill/net/v2/netconf/common/netconferrors"
 	"github.com/damianoneill/net/v2/netconf/testserver"
 
 	assert "github.com/stretchr/testify/require"
@@ -81,6 +83,33 @@ func TestExecuteWithFailingRequest(t *testing.T) {
 	assert.NotNil(t, reply, "Reply should be non-nil")
 }
 
+func TestExecuteWithMultipleErrors(t *testing.T) {
+	ncs := newNCClientSession(t, testserver.NewTestNetconfServer(t).WithRequestHandler(testserver.MultipleErrorsRequestHandler))
+	defer ncs.Close()
+
+	reply, err := ncs.Execute(common.Request(`<get><response/></get>`))
+	assert.Error(t, err, "Expecting exec to fail")
+	assert.NotNil(t, reply, "Reply should be non-nil")
+	assert.Equal(t, "netconf rpc [error] 'locked'; netconf rpc [error] 'bad value'", err.Error(), "Expected error")
+
+	var rpcErrs *common.RPCErrors
+	assert.True(t, errors.As(err, &rpcErrs), "Expected RPCErrors")
+	assert.Len(t, rpcErrs.Errors, 2, "Expected all errors to be reported")
+	assert.Equal(t, uint64(42), rpcErrs.Errors[0].ErrorInfo.SessionID, "Expected lock holder")
+	assert.Equal(t, "too-large", rpcErrs.Errors[1].AppTag, "Expected app tag")
+	assert.Equal(t, "value", rpcErrs.Errors[1].ErrorInfo.BadElement, "Expected bad element")
+	assert.Len(t, rpcErrs.Warnings, 1, "Expected warning")
+	assert.Equal(t, "careful", rpcErrs.Warnings[0].Message, "Expected warning")
+
+	assert.ErrorIs(t, err, netconferrors.ErrLockDenied, "Expected lock denied error")
+	assert.ErrorIs(t, err, netconferrors.ErrInvalidValue, "Expected invalid value error")
+	assert.False(t, errors.Is(err, netconferrors.ErrInUse), "Not expecting in use error")
+
+	var rpcErr *common.RPCError
+	assert.True(t, errors.As(err, &rpcErr), "Expected RPCError")
+	assert.Equal(t, "lock-denied", rpcErr.Tag, "Expected first error")
+}
+
 func TestExecuteFailure(t *testing.T) {
 	ts := testserver.NewTestNetconfServer(t)
 	ncs := newNCClientSession(t, ts)
@@ -170,6 +199,172 @@ func TestExecuteAsyncInterrupted(t *testing.T) {
 	assert.Nil(t, reply, "Reply should be nil")
 }
 
+func TestExecuteContextDeadline(t *testing.T) {
+	ts := testserver.NewTestNetconfServer(t).WithRequestHandler(testserver.DelayedRequestHandler(time.Millisecond * 500))
+	ncs := newNCClientSession(t, ts)
+	defer ncs.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*100)
+	defer cancel()
+
+	reply, err := ncs.ExecuteContext(ctx, common.Request(`<get><late/></get>`))
+	assert.ErrorIs(t, err, context.DeadlineExceeded, "Expecting exec to time out")
+	assert.Nil(t, reply, "Reply should be nil")
+
+	// The late reply must not be delivered to the next request.
+	reply, err = ncs.Execute(common.Request(`<get><prompt/></get>`))
+	assert.NoError(t, err, "Not expecting exec to fail")
+	assert.Equal(t, `<data><prompt/></data>`, reply.Data, "Reply should contain response data")
+}
+
+func TestAbandonedRequestsReleased(t *testing.T) {
+	ts := testserver.NewTestNetconfServer(t).WithRequestHandler(testserver.EchoRequestHandler)
+	for i := 0; i < 3; i++ {
+		ts.WithRequestHandler(testserver.IgnoreRequestHandler)
+	}
+	ncs := newNCClientSession(t, ts)
+	defer ncs.Close()
+
+	_, err := ncs.Execute(common.Request(`<get><numbered/></get>`))
+	assert.NoError(t, err, "Not expecting exec to fail")
+
+	for i := 0; i < 3; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+		_, err = ncs.ExecuteContext(ctx, common.Request(`<get><ignored/></get>`))
+		cancel()
+		assert.ErrorIs(t, err, context.DeadlineExceeded, "Expecting exec to time out")
+	}
+
+	si := ncs.(*sesImpl)
+	si.rchLock.Lock()
+	defer si.rchLock.Unlock()
+	assert.Empty(t, si.pending, "Expected abandoned requests to be released")
+	assert.Empty(t, si.responseq, "Expected abandoned requests to be released")
+}
+
+func TestAbandonedRequestsLimited(t *testing.T) {
+	ts := testserver.NewTestNetconfServer(t)
+	for i := 0; i < maxAbandonedReplies+2; i++ {
+		ts.WithRequestHandler(testserver.IgnoreRequestHandler)
+	}
+	ncs := newNCClientSession(t, ts)
+	defer ncs.Close()
+
+	for i := 0; i < maxAbandonedReplies+2; i++ {
+		ctx, cancel := context.WithCancel(context.Background())
+		rch := make(chan *common.RPCReply, 1)
+		assert.NoError(t, ncs.ExecuteAsyncContext(ctx, common.Request(`<get><ignored/></get>`), rch))
+		cancel()
+	}
+
+	si := ncs.(*sesImpl)
+	assert.Eventually(t, func() bool {
+		si.rchLock.Lock()
+		defer si.rchLock.Unlock()
+		return len(si.pending) == 0 && len(si.responseq) == maxAbandonedReplies
+	}, time.Second, time.Millisecond*10, "Expected abandoned requests to be limited")
+}
+
+func TestExecuteContextCancelled(t *testing.T) {
+	ts := testserver.NewTestNetconfServer(t)
+	ncs := newNCClientSession(t, ts)
+	defer ncs.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	reply, err := ncs.ExecuteContext(ctx, common.Request(`<get><response/></get>`))
+	assert.ErrorIs(t, err, context.Canceled, "Expecting exec to fail")
+	assert.Nil(t, reply, "Reply should be nil")
+	assert.Equal(t, 0, ts.SessionHandler(ncs.ID()).ReqCount(), "Request should not have been sent")
+}
+
+func TestExecuteAsyncContextDeadline(t *testing.T) {
+	ts := testserver.NewTestNetconfServer(t).WithRequestHandler(testserver.DelayedRequestHandler(time.Millisecond * 500))
+	ncs := newNCClientSession(t, ts)
+	defer ncs.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*100)
+	defer cancel()
+
+	rch1 := make(chan *common.RPCReply, 1)
+	err := ncs.ExecuteAsyncContext(ctx, common.Request(`<get><late/></get>`), rch1)
+	assert.NoError(t, err, "Not expecting exec to fail")
+
+	rch2 := make(chan *common.RPCReply)
+	_ = ncs.ExecuteAsync(common.Request(`<get><prompt/></get>`), rch2)
+
+	select {
+	case <-rch1:
+		assert.Fail(t, "Not expecting reply after context deadline")
+	case <-ctx.Done():
+	}
+
+	reply := <-rch2
+	assert.NotNil(t, reply, "Reply should not be nil")
+	assert.Equal(t, `<data><prompt/></data>`, reply.Data, "Reply should contain response data")
+	assert.Len(t, rch1, 0, "Late reply should have been discarded")
+}
+
+func TestExecuteRepliesMatchedByMessageID(t *testing.T) {
+	ts := testserver.NewTestNetconfServer(t).WithRequestHandler(testserver.IgnoreRequestHandler)
+	ncs := newNCClientSession(t, ts)
+	defer ncs.Close()
+
+	rch1 := make(chan *common.RPCReply, 1)
+	_ = ncs.ExecuteAsync(common.Request(`<get><ignored/></get>`), rch1)
+
+	// The server never replies to the first request, so the reply to the second must not be
+	// delivered to the first requester.
+	reply, err := ncs.Execute(common.Request(`<get><response/></get>`))
+	assert.NoError(t, err, "Not expecting exec to fail")
+	assert.Equal(t, `<data><response/></data>`, reply.Data, "Reply should contain response data")
+	assert.Len(t, rch1, 0, "Not expecting reply to first request")
+}
+
+func TestExecuteReplyWithoutMessageID(t *testing.T) {
+	ts := testserver.NewTestNetconfServer(t).
+		WithRequestHandler(testserver.NoMessageIDRequestHandler).
+		WithRequestHandler(testserver.NoMessageIDRequestHandler)
+	ncs := newNCClientSession(t, ts)
+	defer ncs.Close()
+
+	rch1 := make(chan *common.RPCReply, 1)
+	rch2 := make(chan *common.RPCReply, 1)
+	_ = ncs.ExecuteAsync(common.Request(`<get><test1/></get>`), rch1)
+	_ = ncs.ExecuteAsync(common.Request(`<get><test2/></get>`), rch2)
+
+	reply := <-rch1
+	assert.Equal(t, `<data><test1/></data>`, reply.Data, "Reply should contain response data")
+	reply = <-rch2
+	assert.Equal(t, `<data><test2/></data>`, reply.Data, "Reply should contain response data")
+}
+
+func TestExecuteUnsolicitedReply(t *testing.T) {
+	var dropped []error
+	var mu sync.Mutex
+	trace := &ClientTrace{
+		ReplyDropped: func(res *common.RPCReply, err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			assert.Equal(t, "unsolicited", res.MessageID, "Unexpected message id")
+			dropped = append(dropped, err)
+		},
+	}
+
+	ts := testserver.NewTestNetconfServer(t).WithRequestHandler(testserver.UnsolicitedReplyRequestHandler)
+	ncs := newNCClientSessionWithContext(WithClientTrace(context.Background(), trace), t, ts)
+	defer ncs.Close()
+
+	reply, err := ncs.Execute(common.Request(`<get><response/></get>`))
+	assert.NoError(t, err, "Not expecting exec to fail")
+	assert.Equal(t, `<data><response/></data>`, reply.Data, "Reply should contain response data")
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []error{ErrUnmatchedReply}, dropped, "Expected unsolicited reply to be dropped")
+}
+
 func TestSubscribe(t *testing.T) {
 	ts := testserver.NewTestNetconfServer(t)
 	ncs := newNCClientSession(t, ts)
@@ -198,7 +393,7 @@ func TestSubscribe(t *testing.T) {
 	assert.NotNil(t, result, "Expected notification")
 	assert.Equal(t, "netconf-session-start", result.XMLName.Local, "Unexpected event type")
 	assert.Equal(t, "urn:ietf:params:xml:ns:yang:ietf-netconf-notifications", result.XMLName.Space, "Unexpected event NS")
-	assert.NotNil(t, result.EventTime, "Unexpected nil event time")
+	assert.False(t, result.EventTime.IsZero(), "Expected event time")
 	assert.Equal(t, notificationEvent(), result.Event, "Unexpected event XML")
 
 	// Get server to send notifications, wait a while for them to arrive and confirm they've been dropped.
@@ -212,6 +407,36 @@ func TestSubscribe(t *testing.T) {
 	assert.Nil(t, result, "No more notifications expected")
 }
 
+func TestSubscribeWhileReceivingNotifications(t *testing.T) {
+	ts := testserver.NewTestNetconfServer(t)
+	defer ts.Close()
+	ncs := newNCClientSession(t, ts)
+	defer ncs.Close()
+	sh := ts.SessionHandler(ncs.ID())
+
+	req := common.Request(`<ncEvent:create-subscription xmlns:ncEvent="urn:ietf:params:xml:ns:netconf:notification:1.0">` +
+		`</ncEvent:create-subscription>`)
+	_, err := ncs.Subscribe(req, make(chan *common.Notification, 10))
+	assert.NoError(t, err, "Not expecting subscribe to fail")
+
+	// Replace the subscription channel while notifications are being handled.
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 20; i++ {
+			sh.SendNotification(notificationEvent())
+		}
+	}()
+	nch := make(chan *common.Notification, 100)
+	_, err = ncs.Subscribe(req, nch)
+	assert.NoError(t, err, "Not expecting subscribe to fail")
+	wg.Wait()
+
+	sh.SendNotification(notificationEvent())
+	assert.NotNil(t, <-nch, "Expected notification")
+}
+
 func TestConcurrentExecute(t *testing.T) {
 	ts := testserver.NewTestNetconfServer(t)
 	ncs := newNCClientSession(t, ts)
@@ -280,6 +505,43 @@ func BenchmarkTemplateParallel(b *testing.B) {
 	})
 }
 
+func TestEventXML(t *testing.T) {
+	content := `<eventTime>2020-01-02T03:04:05Z</eventTime>` +
+		`<ev:event ev:level="2"><ev:target xmlns:if="urn:if">/if:interfaces</ev:target></ev:event>`
+	start := &xml.StartElement{Attr: []xml.Attr{
+		{Name: xml.Name{Local: "xmlns"}, Value: "urn:ietf:params:xml:ns:netconf:notification:1.0"},
+		{Name: xml.Name{Space: "xmlns", Local: "ev"}, Value: "urn:event"},
+	}}
+	assert.Equal(t, `<ev:event xmlns="urn:ietf:params:xml:ns:netconf:notification:1.0" xmlns:ev="urn:event" ev:level="2">`+
+		`<ev:target xmlns:if="urn:if">/if:interfaces</ev:target></ev:event>`, eventXML(start, content), "Unexpected event XML")
+
+	content = `<eventTime>2020-01-02T03:04:05Z</eventTime>` + notificationEvent()
+	assert.Equal(t, notificationEvent(), eventXML(&xml.StartElement{Attr: start.Attr[:1]}, content), "Expected event XML to be unchanged")
+}
+
+func TestBuildNotificationEventTime(t *testing.T) {
+	var traced error
+	si := &sesImpl{target: "test", trace: &ClientTrace{Error: func(context, target string, err error) { traced = err }}}
+	start := &xml.StartElement{}
+
+	for raw, expected := range map[string]time.Time{
+		"2020-01-02T03:04:05.5Z":    time.Date(2020, 1, 2, 3, 4, 5, 500000000, time.UTC),
+		"2020-01-02T03:04:05+01:00": time.Date(2020, 1, 2, 2, 4, 5, 0, time.UTC),
+		"2020-01-02T03:04:05":       time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+		"2020-01-02T03:04:05+0100":  time.Date(2020, 1, 2, 2, 4, 5, 0, time.UTC),
+	} {
+		n := si.buildNotification(start, &common.NotificationMessage{EventTime: raw})
+		assert.True(t, expected.Equal(n.EventTime), "Unexpected event time for %q: %v", raw, n.EventTime)
+		assert.Equal(t, raw, n.RawEventTime, "Unexpected raw event time")
+		assert.NoError(t, traced, "Not expecting error to be traced")
+	}
+
+	n := si.buildNotification(start, &common.NotificationMessage{EventTime: "yesterday"})
+	assert.True(t, n.EventTime.IsZero(), "Expected zero event time")
+	assert.Equal(t, "yesterday", n.RawEventTime, "Expected raw event time to be kept")
+	assert.Error(t, traced, "Expected error to be traced")
+}
+
 func notificationEvent() string {
 	return `<netconf-session-start xmlns="urn:ietf:params:xml:ns:yang:ietf-netconf-notifications">` +
 		`<username>XXxxxx</username>` +
@@ -300,6 +562,18 @@ func newNCClientSession(t assert.TestingT, ts *testserver.TestNCServer) Session
 	return s
 }
 
+func newNCClientSessionWithContext(ctx context.Context, t assert.TestingT, ts *testserver.TestNCServer) Session {
+	serverAddress := fmt.Sprintf("localhost:%d", ts.Port())
+	sshConfig := &ssh.ClientConfig{
+		User:            testserver.TestUserName,
+		Auth:            []ssh.AuthMethod{ssh.Password(testserver.TestPassword)},
+		HostKeyCallback: ssh.InsecureIgnoreHostKey(), //nolint: gosec
+	}
+	s, err := NewRPCSession(ctx, sshConfig, serverAddress)
+	assert.NoError(t, err, "Failed to create session")
+	return s
+}
+
 func newNCClientSessionWithConfig(t assert.TestingT, ts *testserver.TestNCServer, cfg *Config) Session {
 	serverAddress := fmt.Sprintf("localhost:%d", ts.Port())
 	sshConfig := &ssh.ClientConfig{
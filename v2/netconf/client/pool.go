@@ -0,0 +1,316 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/imdario/mergo"
+
+	"github.com/damianoneill/net/v2/netconf/common"
+)
+
+// DialerFactory creates the Dialer used to establish sessions to a target.
+type DialerFactory func(target string) (Dialer, error)
+
+// HealthCheck verifies that a session is usable, returning an error if it is not.
+type HealthCheck func(ctx context.Context, s Session) error
+
+// PoolConfig defines properties that configure the behaviour of a Pool.
+type PoolConfig struct {
+	// The configuration used for each session created by the pool.
+	SessionConfig *Config
+	// The maximum number of concurrent sessions to each target.
+	MaxSessionsPerTarget int
+	// The time after which an unused session is closed.
+	IdleTimeout time.Duration
+	// A session that has been idle for longer than this is health checked before being lent.
+	HealthCheckInterval time.Duration
+	// The function used to check the health of a session.
+	HealthCheck HealthCheck
+}
+
+// DefaultPoolConfig defines the default pool behaviour.
+var DefaultPoolConfig = &PoolConfig{
+	SessionConfig:        DefaultConfig,
+	MaxSessionsPerTarget: 2,
+	IdleTimeout:          5 * time.Minute,
+	HealthCheckInterval:  30 * time.Second,
+	HealthCheck:          DefaultHealthCheck,
+}
+
+// ErrPoolClosed is returned when a session is requested from a pool that has been closed.
+var ErrPoolClosed = errors.New("netconf session pool is closed")
+
+const healthCheckRequest = `<get><filter type="subtree">` +
+	`<netconf-state xmlns="urn:ietf:params:xml:ns:yang:ietf-netconf-monitoring"><datastores/></netconf-state>` +
+	`</filter></get>`
+
+// DefaultHealthCheck checks the health of a session by retrieving the netconf-state datastores.
+// A server that replies with an rpc-error, for example because it does not support ietf-netconf-monitoring,
+// is still considered healthy.
+func DefaultHealthCheck(ctx context.Context, s Session) error {
+	_, err := s.ExecuteContext(ctx, common.Request(healthCheckRequest))
+	var rpcErr *common.RPCError
+	if errors.As(err, &rpcErr) {
+		return nil
+	}
+	return err
+}
+
+// Pool manages sessions to multiple targets, lending them to callers and retaining them for reuse once
+// they have been returned.
+// Sessions are created on demand, up to a configurable limit per target; callers requesting a session
+// when the limit has been reached wait until one is returned.
+// A Pool is safe for concurrent use by multiple goroutines.
+type Pool struct {
+	ctx     context.Context
+	factory DialerFactory
+	cfg     *PoolConfig
+
+	mu      sync.Mutex
+	targets map[string]*poolTarget
+	closed  bool
+
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+// poolTarget holds the sessions to a single target.
+type poolTarget struct {
+	dialer Dialer
+	// sem holds a token for each session that is lent, limiting the number of concurrent sessions.
+	sem chan struct{}
+	// idle holds the sessions available for reuse, most recently used last.
+	idle  []*idleSession
+	inUse int
+}
+
+type idleSession struct {
+	s     Session
+	since time.Time
+}
+
+// PooledSession is a Session lent by a Pool.
+// Close returns the session to the pool, rather than closing it; Discard should be used instead if the
+// session is known to be unusable.
+type PooledSession struct {
+	Session
+	pool   *Pool
+	target string
+	once   sync.Once
+}
+
+// NewPool creates a session pool that uses the factory to create dialers for each target.
+// The context is used for tracing, and for the creation of sessions.
+func NewPool(ctx context.Context, factory DialerFactory, cfg *PoolConfig) *Pool {
+	resolvedConfig := *cfg
+	_ = mergo.Merge(&resolvedConfig, DefaultPoolConfig)
+
+	p := &Pool{
+		ctx:     ctx,
+		factory: factory,
+		cfg:     &resolvedConfig,
+		targets: make(map[string]*poolTarget),
+		done:    make(chan struct{}),
+	}
+
+	p.wg.Add(1)
+	go p.evictIdle()
+	return p
+}
+
+// Get borrows a session to the target, creating one if no idle session is available.
+// If the maximum number of sessions to the target are already in use, Get waits until one is returned or
+// the context is done.
+// The session must be returned to the pool, by calling Close or Discard, when it is no longer required.
+func (p *Pool) Get(ctx context.Context, target string) (*PooledSession, error) {
+	pt, err := p.target(target)
+	if err != nil {
+		return nil, err
+	}
+
+	select {
+	case pt.sem <- struct{}{}:
+	case <-ctx.Done():
+		p.release(target, pt)
+		return nil, ctx.Err()
+	case <-p.done:
+		p.release(target, pt)
+		return nil, ErrPoolClosed
+	}
+
+	s, err := p.session(ctx, pt)
+	if err != nil {
+		<-pt.sem
+		p.release(target, pt)
+		return nil, err
+	}
+	return &PooledSession{Session: s, pool: p, target: target}, nil
+}
+
+// Close closes the pool, and any idle sessions. Sessions that are currently lent are closed when they
+// are returned.
+func (p *Pool) Close() {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return
+	}
+	p.closed = true
+	close(p.done)
+
+	var idle []*idleSession
+	for _, pt := range p.targets {
+		idle = append(idle, pt.idle...)
+		pt.idle = nil
+	}
+	p.mu.Unlock()
+
+	for _, is := range idle {
+		is.s.Close()
+	}
+	p.wg.Wait()
+}
+
+// Close returns the session to the pool.
+func (ps *PooledSession) Close() {
+	ps.once.Do(func() {
+		ps.pool.put(ps.target, ps.Session, false)
+	})
+}
+
+// Discard closes the session, and releases its place in the pool.
+func (ps *PooledSession) Discard() {
+	ps.once.Do(func() {
+		ps.pool.put(ps.target, ps.Session, true)
+	})
+}
+
+// target returns the pool entry for the target, creating it if necessary, and registers the caller's use
+// of it.
+func (p *Pool) target(target string) (*poolTarget, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return nil, ErrPoolClosed
+	}
+
+	pt, ok := p.targets[target]
+	if !ok {
+		dialer, err := p.factory(target)
+		if err != nil {
+			return nil, err
+		}
+		pt = &poolTarget{dialer: dialer, sem: make(chan struct{}, p.cfg.MaxSessionsPerTarget)}
+		p.targets[target] = pt
+	}
+	pt.inUse++
+	return pt, nil
+}
+
+// session delivers a healthy idle session, or creates a new one if there are none.
+func (p *Pool) session(ctx context.Context, pt *poolTarget) (Session, error) {
+	for {
+		p.mu.Lock()
+		if len(pt.idle) == 0 {
+			p.mu.Unlock()
+			return NewRPCSessionFromDialer(p.ctx, pt.dialer, p.cfg.SessionConfig)
+		}
+		is := pt.idle[len(pt.idle)-1]
+		pt.idle = pt.idle[:len(pt.idle)-1]
+		p.mu.Unlock()
+
+		if p.healthy(ctx, is) {
+			return is.s, nil
+		}
+		is.s.Close()
+	}
+}
+
+func (p *Pool) healthy(ctx context.Context, is *idleSession) bool {
+	if isClosed(is.s) {
+		return false
+	}
+	if time.Since(is.since) < p.cfg.HealthCheckInterval {
+		return true
+	}
+	return p.cfg.HealthCheck(ctx, is.s) == nil
+}
+
+// put returns a lent session to the pool.
+func (p *Pool) put(target string, s Session, discard bool) {
+	p.mu.Lock()
+	pt := p.targets[target]
+	retain := !discard && !p.closed && !isClosed(s)
+	if retain {
+		pt.idle = append(pt.idle, &idleSession{s: s, since: time.Now()})
+	}
+	p.mu.Unlock()
+
+	if !retain {
+		s.Close()
+	}
+	<-pt.sem
+	p.release(target, pt)
+}
+
+// release deregisters a caller's use of the target, removing the pool entry once it is unused.
+func (p *Pool) release(target string, pt *poolTarget) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	pt.inUse--
+	if pt.inUse == 0 && len(pt.idle) == 0 {
+		delete(p.targets, target)
+	}
+}
+
+// evictIdle periodically closes sessions that have been idle for longer than the idle timeout.
+func (p *Pool) evictIdle() {
+	defer p.wg.Done()
+
+	ticker := time.NewTicker(p.cfg.IdleTimeout / 2)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			for _, s := range p.expired() {
+				s.Close()
+			}
+		case <-p.done:
+			return
+		}
+	}
+}
+
+// expired removes and returns the sessions that have exceeded the idle timeout.
+func (p *Pool) expired() (sessions []Session) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for target, pt := range p.targets {
+		// Idle sessions are ordered by last use, so retain those after the first that has not expired.
+		n := 0
+		for n < len(pt.idle) && time.Since(pt.idle[n].since) >= p.cfg.IdleTimeout {
+			sessions = append(sessions, pt.idle[n].s)
+			n++
+		}
+		pt.idle = pt.idle[n:]
+		if pt.inUse == 0 && len(pt.idle) == 0 {
+			delete(p.targets, target)
+		}
+	}
+	return
+}
+
+// isClosed reports whether the underlying transport of the session has been closed.
+func isClosed(s Session) bool {
+	select {
+	case <-sessionDone(s):
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,240 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+
+	"github.com/damianoneill/net/v2/netconf/common"
+	"github.com/damianoneill/net/v2/netconf/testserver"
+
+	assert "github.com/stretchr/testify/require"
+)
+
+func TestPoolReusesSessions(t *testing.T) {
+	ts := testserver.NewTestNetconfServer(t)
+	defer ts.Close()
+
+	p := NewPool(context.Background(), testDialerFactory, &PoolConfig{})
+	defer p.Close()
+
+	ps, err := p.Get(context.Background(), testTarget(ts))
+	assert.NoError(t, err, "Not expecting get to fail")
+	id := ps.ID()
+	reply, err := ps.Execute(common.Request(`<get><response/></get>`))
+	assert.NoError(t, err, "Not expecting exec to fail")
+	assert.Equal(t, `<data><response/></data>`, reply.Data, "Reply should contain response data")
+	ps.Close()
+	ps.Close()
+
+	ps, err = p.Get(context.Background(), testTarget(ts))
+	assert.NoError(t, err, "Not expecting get to fail")
+	assert.Equal(t, id, ps.ID(), "Expected idle session to be reused")
+	ps.Close()
+}
+
+func TestPoolLimitsSessionsPerTarget(t *testing.T) {
+	ts := testserver.NewTestNetconfServer(t)
+	defer ts.Close()
+
+	p := NewPool(context.Background(), testDialerFactory, &PoolConfig{MaxSessionsPerTarget: 1})
+	defer p.Close()
+
+	ps, err := p.Get(context.Background(), testTarget(ts))
+	assert.NoError(t, err, "Not expecting get to fail")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = p.Get(ctx, testTarget(ts))
+	assert.ErrorIs(t, err, context.DeadlineExceeded, "Expecting get to wait for session")
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		ps.Close()
+	}()
+	ps2, err := p.Get(context.Background(), testTarget(ts))
+	assert.NoError(t, err, "Not expecting get to fail")
+	assert.Equal(t, ps.ID(), ps2.ID(), "Expected returned session to be reused")
+	ps2.Close()
+}
+
+func TestPoolConcurrentUse(t *testing.T) {
+	ts1 := testserver.NewTestNetconfServer(t)
+	defer ts1.Close()
+	ts2 := testserver.NewTestNetconfServer(t)
+	defer ts2.Close()
+
+	p := NewPool(context.Background(), testDialerFactory, &PoolConfig{MaxSessionsPerTarget: 2})
+	defer p.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		target := testTarget(ts1)
+		if i%2 == 0 {
+			target = testTarget(ts2)
+		}
+		wg.Add(1)
+		go func(target string, i int) {
+			defer wg.Done()
+			ps, err := p.Get(context.Background(), target)
+			assert.NoError(t, err, "Not expecting get to fail")
+			defer ps.Close()
+
+			assert.LessOrEqual(t, ps.ID(), uint64(2), "Expected at most two sessions per target")
+			reply, err := ps.Execute(common.Request(fmt.Sprintf(`<get><response%d/></get>`, i)))
+			assert.NoError(t, err, "Not expecting exec to fail")
+			assert.Equal(t, fmt.Sprintf(`<data><response%d/></data>`, i), reply.Data, "Reply should contain response data")
+		}(target, i)
+	}
+	wg.Wait()
+}
+
+func TestPoolDiscard(t *testing.T) {
+	ts := testserver.NewTestNetconfServer(t)
+	defer ts.Close()
+
+	p := NewPool(context.Background(), testDialerFactory, &PoolConfig{MaxSessionsPerTarget: 1})
+	defer p.Close()
+
+	ps, err := p.Get(context.Background(), testTarget(ts))
+	assert.NoError(t, err, "Not expecting get to fail")
+	id := ps.ID()
+	ps.Discard()
+
+	ps, err = p.Get(context.Background(), testTarget(ts))
+	assert.NoError(t, err, "Not expecting get to fail")
+	assert.NotEqual(t, id, ps.ID(), "Expected new session")
+	ps.Close()
+}
+
+func TestPoolReplacesClosedSession(t *testing.T) {
+	ts := testserver.NewTestNetconfServer(t)
+	defer ts.Close()
+
+	p := NewPool(context.Background(), testDialerFactory, &PoolConfig{})
+	defer p.Close()
+
+	ps, err := p.Get(context.Background(), testTarget(ts))
+	assert.NoError(t, err, "Not expecting get to fail")
+	id := ps.ID()
+	ps.Close()
+
+	ts.SessionHandler(id).Close()
+	assert.Eventually(t, func() bool {
+		ps, err = p.Get(context.Background(), testTarget(ts))
+		assert.NoError(t, err, "Not expecting get to fail")
+		defer ps.Close()
+		return ps.ID() != id
+	}, 5*time.Second, 10*time.Millisecond, "Expected closed session to be replaced")
+}
+
+func TestPoolHealthCheck(t *testing.T) {
+	ts := testserver.NewTestNetconfServer(t)
+	defer ts.Close()
+
+	var checked []uint64
+	p := NewPool(context.Background(), testDialerFactory, &PoolConfig{
+		HealthCheckInterval: time.Nanosecond,
+		HealthCheck: func(ctx context.Context, s Session) error {
+			checked = append(checked, s.ID())
+			return errors.New("unhealthy")
+		},
+	})
+	defer p.Close()
+
+	ps, err := p.Get(context.Background(), testTarget(ts))
+	assert.NoError(t, err, "Not expecting get to fail")
+	id := ps.ID()
+	ps.Close()
+
+	ps, err = p.Get(context.Background(), testTarget(ts))
+	assert.NoError(t, err, "Not expecting get to fail")
+	assert.NotEqual(t, id, ps.ID(), "Expected unhealthy session to be replaced")
+	assert.Equal(t, []uint64{id}, checked, "Expected idle session to be checked")
+	ps.Close()
+}
+
+func TestDefaultHealthCheck(t *testing.T) {
+	ts := testserver.NewTestNetconfServer(t).
+		WithRequestHandler(testserver.EchoRequestHandler).
+		WithRequestHandler(testserver.FailingRequestHandler)
+	defer ts.Close()
+
+	ncs := newNCClientSession(t, ts)
+	assert.NoError(t, DefaultHealthCheck(context.Background(), ncs), "Expected session to be healthy")
+	assert.NoError(t, DefaultHealthCheck(context.Background(), ncs), "Expected rpc-error to be tolerated")
+
+	ncs.Close()
+	assert.Error(t, DefaultHealthCheck(context.Background(), ncs), "Expected closed session to be unhealthy")
+}
+
+func TestPoolEvictsIdleSessions(t *testing.T) {
+	ts := testserver.NewTestNetconfServer(t)
+	defer ts.Close()
+
+	p := NewPool(context.Background(), testDialerFactory, &PoolConfig{IdleTimeout: 20 * time.Millisecond})
+	defer p.Close()
+
+	ps, err := p.Get(context.Background(), testTarget(ts))
+	assert.NoError(t, err, "Not expecting get to fail")
+	s := ps.Session
+	ps.Close()
+
+	assert.Eventually(t, func() bool { return isClosed(s) }, 5*time.Second, 10*time.Millisecond,
+		"Expected idle session to be closed")
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	assert.Empty(t, p.targets, "Expected unused target to be removed")
+}
+
+func TestPoolClose(t *testing.T) {
+	ts := testserver.NewTestNetconfServer(t)
+	defer ts.Close()
+
+	p := NewPool(context.Background(), testDialerFactory, &PoolConfig{})
+
+	idle, err := p.Get(context.Background(), testTarget(ts))
+	assert.NoError(t, err, "Not expecting get to fail")
+	lent, err := p.Get(context.Background(), testTarget(ts))
+	assert.NoError(t, err, "Not expecting get to fail")
+	idle.Close()
+
+	p.Close()
+	assert.Eventually(t, func() bool { return isClosed(idle.Session) }, 5*time.Second, 10*time.Millisecond,
+		"Expected idle session to be closed")
+	assert.False(t, isClosed(lent.Session), "Expected lent session to remain open")
+
+	lent.Close()
+	assert.Eventually(t, func() bool { return isClosed(lent.Session) }, 5*time.Second, 10*time.Millisecond,
+		"Expected returned session to be closed")
+
+	_, err = p.Get(context.Background(), testTarget(ts))
+	assert.ErrorIs(t, err, ErrPoolClosed, "Expecting get to fail")
+}
+
+func TestPoolDialerFactoryFailure(t *testing.T) {
+	p := NewPool(context.Background(), func(target string) (Dialer, error) {
+		return nil, errors.New("unknown target")
+	}, &PoolConfig{})
+	defer p.Close()
+
+	_, err := p.Get(context.Background(), "unknown")
+	assert.EqualError(t, err, "unknown target")
+}
+
+func testTarget(ts *testserver.TestNCServer) string {
+	return fmt.Sprintf("localhost:%d", ts.Port())
+}
+
+func testDialerFactory(target string) (Dialer, error) {
+	return NewSSHDialer(target, &ssh.ClientConfig{
+		User:            testserver.TestUserName,
+		Auth:            []ssh.AuthMethod{ssh.Password(testserver.TestPassword)},
+		HostKeyCallback: ssh.InsecureIgnoreHostKey(), //nolint: gosec
+	}), nil
+}
@@ -0,0 +1,405 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/imdario/mergo"
+
+	"github.com/damianoneill/net/v2/netconf/common"
+)
+
+// ConnectionState describes the state of the connection underlying a ResilientSession.
+type ConnectionState int
+
+// Define the connection states reported by a ResilientSession.
+const (
+	// StateConnecting indicates that a connection attempt is in progress.
+	StateConnecting ConnectionState = iota
+	// StateConnected indicates that a session has been established and the setup requests replayed.
+	StateConnected
+	// StateDisconnected indicates that the connection has been lost, or a connection attempt has failed.
+	StateDisconnected
+	// StateClosed indicates that the session has been closed, or reconnection abandoned.
+	StateClosed
+)
+
+func (s ConnectionState) String() string {
+	switch s {
+	case StateConnecting:
+		return "connecting"
+	case StateConnected:
+		return "connected"
+	case StateDisconnected:
+		return "disconnected"
+	case StateClosed:
+		return "closed"
+	default:
+		return "unknown"
+	}
+}
+
+// ReconnectConfig defines properties that configure the reconnection behaviour of a ResilientSession.
+type ReconnectConfig struct {
+	// The delay before the first reconnection attempt.
+	InitialBackoff time.Duration
+	// The maximum delay between reconnection attempts.
+	MaxBackoff time.Duration
+	// The factor by which the delay is increased after each failed attempt.
+	Multiplier float64
+	// The proportion of the delay, between 0 and 1, by which each delay is randomly varied.
+	Jitter float64
+	// The maximum number of consecutive failed reconnection attempts, after which the session is closed.
+	// Zero indicates that there is no limit.
+	MaxAttempts int
+	// Requests that will be executed, in order, each time a session is established, for example to restore
+	// session state such as locks. If any request fails, the session is discarded and reconnection retried.
+	SetupRequests []common.Request
+}
+
+// DefaultReconnectConfig defines the default reconnection behaviour.
+var DefaultReconnectConfig = &ReconnectConfig{
+	InitialBackoff: time.Second,
+	MaxBackoff:     time.Minute,
+	Multiplier:     2,
+	Jitter:         0.2,
+}
+
+// ErrNotConnected is returned by a ResilientSession if a request is made while the session is not connected.
+var ErrNotConnected = errors.New("netconf session is not connected")
+
+// ResilientSession is a Session that automatically re-establishes the underlying netconf session, using
+// exponential backoff, when the transport connection is lost.
+// Requests made while the session is reconnecting fail with ErrNotConnected; WaitConnected can be used to
+// wait for the connection to be restored.
+// Notification channels supplied to Subscribe are closed when the underlying session is lost, so
+// subscriptions must be re-established, with a new channel, after reconnection.
+type ResilientSession struct {
+	ctx    context.Context
+	dialer Dialer
+	cfg    *Config
+	rcfg   *ReconnectConfig
+	trace  *ClientTrace
+
+	mu        sync.RWMutex
+	current   Session
+	state     ConnectionState
+	connected chan struct{}
+
+	closing chan struct{}
+	once    sync.Once
+	wg      sync.WaitGroup
+
+	// Closed when the session is closed, or reconnection is abandoned.
+	done     chan struct{}
+	doneOnce sync.Once
+}
+
+// NewResilientSession establishes a netconf session using the dialer, which will be re-established when
+// the transport connection is lost.
+// The initial connection is made synchronously, and an error returned if it fails.
+// The context is used for tracing and dialing; reconnection will stop when it is done.
+func NewResilientSession(ctx context.Context, dialer Dialer, cfg *Config, rcfg *ReconnectConfig) (*ResilientSession, error) {
+	resolvedConfig := *rcfg
+	_ = mergo.Merge(&resolvedConfig, DefaultReconnectConfig)
+
+	rs := &ResilientSession{
+		ctx:       ctx,
+		dialer:    dialer,
+		cfg:       cfg,
+		rcfg:      &resolvedConfig,
+		trace:     ContextClientTrace(ctx),
+		connected: make(chan struct{}),
+		closing:   make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+
+	rs.setState(StateConnecting, nil)
+	s, err := rs.connect()
+	if err != nil {
+		rs.setState(StateClosed, err)
+		return nil, err
+	}
+	_ = rs.setSession(s)
+
+	rs.wg.Add(1)
+	go rs.monitor(s)
+	return rs, nil
+}
+
+// State returns the current connection state.
+func (rs *ResilientSession) State() ConnectionState {
+	rs.mu.RLock()
+	defer rs.mu.RUnlock()
+	return rs.state
+}
+
+// WaitConnected waits until the session is connected, the context is done or the session is closed.
+// ErrNotConnected is returned if the session is closed, or reconnection has been abandoned.
+func (rs *ResilientSession) WaitConnected(ctx context.Context) error {
+	rs.mu.RLock()
+	connected := rs.connected
+	rs.mu.RUnlock()
+
+	select {
+	case <-rs.done:
+		return ErrNotConnected
+	default:
+	}
+
+	select {
+	case <-connected:
+		return nil
+	case <-rs.done:
+		return ErrNotConnected
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Execute executes an RPC request on the current session.
+func (rs *ResilientSession) Execute(req common.Request) (*common.RPCReply, error) {
+	s, err := rs.session()
+	if err != nil {
+		return nil, err
+	}
+	return s.Execute(req)
+}
+
+// ExecuteAsync submits an RPC request for execution on the current session.
+func (rs *ResilientSession) ExecuteAsync(req common.Request, rchan chan *common.RPCReply) error {
+	s, err := rs.session()
+	if err != nil {
+		return err
+	}
+	return s.ExecuteAsync(req, rchan)
+}
+
+// ExecuteContext executes an RPC request on the current session.
+func (rs *ResilientSession) ExecuteContext(ctx context.Context, req common.Request) (*common.RPCReply, error) {
+	s, err := rs.session()
+	if err != nil {
+		return nil, err
+	}
+	return s.ExecuteContext(ctx, req)
+}
+
+// ExecuteAsyncContext submits an RPC request for execution on the current session.
+func (rs *ResilientSession) ExecuteAsyncContext(ctx context.Context, req common.Request, rchan chan *common.RPCReply) error {
+	s, err := rs.session()
+	if err != nil {
+		return err
+	}
+	return s.ExecuteAsyncContext(ctx, req, rchan)
+}
+
+// ExecuteStream executes an RPC request on the current session, returning a stream that delivers the reply.
+func (rs *ResilientSession) ExecuteStream(ctx context.Context, req common.Request) (*ReplyStream, error) {
+	s, err := rs.session()
+	if err != nil {
+		return nil, err
+	}
+	return s.ExecuteStream(ctx, req)
+}
+
+// Subscribe issues an RPC request on the current session, and arranges for notifications to be sent to the
+// channel.
+func (rs *ResilientSession) Subscribe(req common.Request, nchan chan *common.Notification) (*common.RPCReply, error) {
+	s, err := rs.session()
+	if err != nil {
+		return nil, err
+	}
+	return s.Subscribe(req, nchan)
+}
+
+// AddNotificationSink registers a notification sink with the current session; the sink is closed when the
+// underlying session is lost.
+func (rs *ResilientSession) AddNotificationSink(cfg *SinkConfig) *NotificationSink {
+	s, err := rs.session()
+	if err != nil {
+		sink := newNotificationSink(cfg, rs.trace)
+		sink.close()
+		return sink
+	}
+	return s.AddNotificationSink(cfg)
+}
+
+// RemoveNotificationSink removes a notification sink from the current session.
+func (rs *ResilientSession) RemoveNotificationSink(sink *NotificationSink) {
+	if s, err := rs.session(); err == nil {
+		s.RemoveNotificationSink(sink)
+	}
+}
+
+// NotificationDrops delivers the drop counts of the notification sinks of the current session, or nil if the
+// session is not connected.
+func (rs *ResilientSession) NotificationDrops() map[string]uint64 {
+	if s, err := rs.session(); err == nil {
+		return s.NotificationDrops()
+	}
+	return nil
+}
+
+// Close closes the session and stops any further reconnection.
+func (rs *ResilientSession) Close() {
+	rs.once.Do(func() {
+		rs.mu.Lock()
+		close(rs.closing)
+		rs.finish()
+		s := rs.current
+		rs.current = nil
+		rs.mu.Unlock()
+
+		if s != nil {
+			s.Close()
+		}
+		rs.wg.Wait()
+		rs.setState(StateClosed, nil)
+	})
+}
+
+// ID delivers the server-allocated id of the current session, or zero if the session is not connected.
+func (rs *ResilientSession) ID() uint64 {
+	if s, err := rs.session(); err == nil {
+		return s.ID()
+	}
+	return 0
+}
+
+// ServerCapabilities delivers the capabilities of the current session, or nil if the session is not connected.
+func (rs *ResilientSession) ServerCapabilities() []string {
+	if s, err := rs.session(); err == nil {
+		return s.ServerCapabilities()
+	}
+	return nil
+}
+
+func (rs *ResilientSession) session() (Session, error) {
+	rs.mu.RLock()
+	defer rs.mu.RUnlock()
+	if rs.current == nil {
+		return nil, ErrNotConnected
+	}
+	return rs.current, nil
+}
+
+// finish marks the end of the resilient session, once it is closed or reconnection is abandoned.
+func (rs *ResilientSession) finish() {
+	rs.doneOnce.Do(func() {
+		close(rs.done)
+	})
+}
+
+// monitor waits for the session to end, and re-establishes it unless the resilient session has been closed.
+func (rs *ResilientSession) monitor(s Session) {
+	defer rs.wg.Done()
+
+	for {
+		select {
+		case <-sessionDone(s):
+		case <-rs.closing:
+			return
+		}
+
+		rs.mu.Lock()
+		rs.current = nil
+		rs.connected = make(chan struct{})
+		rs.mu.Unlock()
+		rs.setState(StateDisconnected, errSessionLost)
+
+		var err error
+		if s, err = rs.reconnect(); err != nil {
+			if err != errClosing {
+				rs.finish()
+				rs.setState(StateClosed, err)
+			}
+			return
+		}
+		if !rs.setSession(s) {
+			// Closed while reconnecting.
+			s.Close()
+			return
+		}
+	}
+}
+
+var (
+	errSessionLost = errors.New("netconf session lost")
+	errClosing     = errors.New("session closing")
+)
+
+// reconnect attempts to establish a new session, waiting between attempts according to the backoff
+// configuration.
+func (rs *ResilientSession) reconnect() (Session, error) {
+	for attempt := 0; rs.rcfg.MaxAttempts == 0 || attempt < rs.rcfg.MaxAttempts; attempt++ {
+		select {
+		case <-time.After(rs.backoff(attempt)):
+		case <-rs.closing:
+			return nil, errClosing
+		case <-rs.ctx.Done():
+			return nil, rs.ctx.Err()
+		}
+
+		rs.setState(StateConnecting, nil)
+		s, err := rs.connect()
+		if err == nil {
+			return s, nil
+		}
+		rs.setState(StateDisconnected, err)
+	}
+	return nil, ErrNotConnected
+}
+
+// connect establishes a session and executes the setup requests.
+func (rs *ResilientSession) connect() (Session, error) {
+	s, err := NewRPCSessionFromDialer(rs.ctx, rs.dialer, rs.cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, req := range rs.rcfg.SetupRequests {
+		if _, err = s.ExecuteContext(rs.ctx, req); err != nil {
+			s.Close()
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// setSession makes s the current session, returning false if the resilient session has been closed.
+func (rs *ResilientSession) setSession(s Session) bool {
+	rs.mu.Lock()
+	select {
+	case <-rs.closing:
+		rs.mu.Unlock()
+		return false
+	default:
+	}
+	rs.current = s
+	close(rs.connected)
+	rs.mu.Unlock()
+
+	rs.setState(StateConnected, nil)
+	return true
+}
+
+func (rs *ResilientSession) setState(state ConnectionState, err error) {
+	rs.mu.Lock()
+	rs.state = state
+	rs.mu.Unlock()
+	rs.trace.ConnectionStateChanged(rs.dialer.Target(), state, err)
+}
+
+// backoff returns the delay before the specified reconnection attempt.
+func (rs *ResilientSession) backoff(attempt int) time.Duration {
+	delay := float64(rs.rcfg.InitialBackoff) * math.Pow(rs.rcfg.Multiplier, float64(attempt))
+	if max := float64(rs.rcfg.MaxBackoff); delay > max {
+		delay = max
+	}
+	delay *= 1 + rs.rcfg.Jitter*(2*rand.Float64()-1) //nolint:gosec
+	return time.Duration(delay)
+}
@@ -0,0 +1,173 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+
+	"github.com/damianoneill/net/v2/netconf/common"
+	"github.com/damianoneill/net/v2/netconf/testserver"
+
+	assert "github.com/stretchr/testify/require"
+)
+
+func TestResilientSessionReconnects(t *testing.T) {
+	ts := testserver.NewTestNetconfServer(t)
+	defer ts.Close()
+
+	states, ctx := stateRecorder(context.Background())
+	rs, err := NewResilientSession(ctx, testDialer(ts), DefaultConfig, testReconnectConfig())
+	assert.NoError(t, err, "Not expecting new session to fail")
+	defer rs.Close()
+
+	var _ Session = rs
+	assert.Equal(t, StateConnected, rs.State(), "Expected session to be connected")
+	firstID := rs.ID()
+
+	// Drop the session from the server side.
+	ts.SessionHandler(firstID).Close()
+	states.waitFor(t, StateDisconnected)
+
+	tctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	assert.NoError(t, rs.WaitConnected(tctx), "Expected session to reconnect")
+	assert.NotEqual(t, firstID, rs.ID(), "Expected new session")
+
+	reply, err := rs.Execute(common.Request(`<get><response/></get>`))
+	assert.NoError(t, err, "Not expecting exec to fail")
+	assert.Equal(t, `<data><response/></data>`, reply.Data, "Reply should contain response data")
+
+	assert.Equal(t, []ConnectionState{StateConnecting, StateConnected, StateDisconnected, StateConnecting, StateConnected},
+		states.get(), "Unexpected state changes")
+}
+
+func TestResilientSessionReplaysSetupRequests(t *testing.T) {
+	ts := testserver.NewTestNetconfServer(t)
+	defer ts.Close()
+
+	rcfg := testReconnectConfig()
+	rcfg.SetupRequests = []common.Request{`<get><setup1/></get>`, `<get><setup2/></get>`}
+
+	rs, err := NewResilientSession(context.Background(), testDialer(ts), DefaultConfig, rcfg)
+	assert.NoError(t, err, "Not expecting new session to fail")
+	defer rs.Close()
+	assert.Equal(t, 2, ts.SessionHandler(rs.ID()).ReqCount(), "Expected setup requests to be executed")
+
+	ts.SessionHandler(rs.ID()).Close()
+	assert.Eventually(t, func() bool { return rs.State() == StateConnected && rs.ID() != 1 }, 5*time.Second, 10*time.Millisecond,
+		"Expected session to reconnect")
+
+	sh := ts.SessionHandler(rs.ID())
+	assert.Equal(t, 2, sh.ReqCount(), "Expected setup requests to be replayed")
+	assert.Equal(t, `<setup2/>`, sh.LastReq().Body, "Unexpected setup request")
+}
+
+func TestResilientSessionAbandonsReconnection(t *testing.T) {
+	ts := testserver.NewTestNetconfServer(t)
+
+	states, ctx := stateRecorder(context.Background())
+	rcfg := testReconnectConfig()
+	rcfg.MaxAttempts = 2
+	rs, err := NewResilientSession(ctx, testDialer(ts), DefaultConfig, rcfg)
+	assert.NoError(t, err, "Not expecting new session to fail")
+	defer rs.Close()
+
+	// Stop the server, so that reconnection fails.
+	ts.Close()
+	states.waitFor(t, StateClosed)
+
+	_, err = rs.Execute(common.Request(`<get><response/></get>`))
+	assert.ErrorIs(t, err, ErrNotConnected, "Expecting exec to fail")
+	assert.ErrorIs(t, rs.WaitConnected(context.Background()), ErrNotConnected, "Expecting wait to fail")
+	assert.Equal(t, []ConnectionState{StateConnecting, StateConnected, StateDisconnected,
+		StateConnecting, StateDisconnected, StateConnecting, StateDisconnected, StateClosed},
+		states.get(), "Unexpected state changes")
+}
+
+func TestResilientSessionClose(t *testing.T) {
+	ts := testserver.NewTestNetconfServer(t)
+	defer ts.Close()
+
+	rs, err := NewResilientSession(context.Background(), testDialer(ts), DefaultConfig, testReconnectConfig())
+	assert.NoError(t, err, "Not expecting new session to fail")
+
+	rs.Close()
+	assert.Equal(t, StateClosed, rs.State(), "Expected session to be closed")
+	assert.ErrorIs(t, rs.WaitConnected(context.Background()), ErrNotConnected, "Expecting wait to fail")
+
+	_, err = rs.Execute(common.Request(`<get><response/></get>`))
+	assert.ErrorIs(t, err, ErrNotConnected, "Expecting exec to fail")
+}
+
+func TestResilientSessionInitialFailure(t *testing.T) {
+	ts := testserver.NewTestNetconfServer(t)
+	dialer := testDialer(ts)
+	ts.Close()
+
+	rs, err := NewResilientSession(context.Background(), dialer, DefaultConfig, testReconnectConfig())
+	assert.Error(t, err, "Expecting new session to fail")
+	assert.Nil(t, rs, "Session should be nil")
+}
+
+func TestResilientSessionBackoff(t *testing.T) {
+	rs := &ResilientSession{rcfg: &ReconnectConfig{InitialBackoff: time.Second, MaxBackoff: 5 * time.Second, Multiplier: 2}}
+	assert.Equal(t, time.Second, rs.backoff(0))
+	assert.Equal(t, 2*time.Second, rs.backoff(1))
+	assert.Equal(t, 4*time.Second, rs.backoff(2))
+	assert.Equal(t, 5*time.Second, rs.backoff(3))
+
+	rs.rcfg.Jitter = 0.5
+	for i := 0; i < 10; i++ {
+		delay := rs.backoff(1)
+		assert.True(t, delay >= time.Second && delay <= 3*time.Second, "Delay %s outside jitter range", delay)
+	}
+}
+
+func testDialer(ts *testserver.TestNCServer) Dialer {
+	return NewSSHDialer(fmt.Sprintf("localhost:%d", ts.Port()), &ssh.ClientConfig{
+		User:            testserver.TestUserName,
+		Auth:            []ssh.AuthMethod{ssh.Password(testserver.TestPassword)},
+		HostKeyCallback: ssh.InsecureIgnoreHostKey(), //nolint: gosec
+	})
+}
+
+func testReconnectConfig() *ReconnectConfig {
+	return &ReconnectConfig{InitialBackoff: 10 * time.Millisecond, MaxBackoff: 50 * time.Millisecond}
+}
+
+type stateLog struct {
+	mu     sync.Mutex
+	states []ConnectionState
+}
+
+func stateRecorder(ctx context.Context) (*stateLog, context.Context) {
+	sl := &stateLog{}
+	return sl, WithClientTrace(ctx, &ClientTrace{
+		ConnectionStateChanged: func(target string, state ConnectionState, err error) {
+			sl.mu.Lock()
+			defer sl.mu.Unlock()
+			sl.states = append(sl.states, state)
+		},
+	})
+}
+
+func (sl *stateLog) get() []ConnectionState {
+	sl.mu.Lock()
+	defer sl.mu.Unlock()
+	return append([]ConnectionState(nil), sl.states...)
+}
+
+func (sl *stateLog) waitFor(t *testing.T, state ConnectionState) {
+	assert.Eventually(t, func() bool {
+		for _, s := range sl.get() {
+			if s == state {
+				return true
+			}
+		}
+		return false
+	}, 5*time.Second, 10*time.Millisecond, "Expected state %s", state)
+}
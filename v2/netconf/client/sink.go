@@ -0,0 +1,191 @@
+package client
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/damianoneill/net/v2/netconf/common"
+)
+
+// OverflowPolicy defines how a notification sink handles a notification when its buffer is full.
+type OverflowPolicy int
+
+// Define the overflow policies of a notification sink.
+const (
+	// DropNewest discards the notification that has been received.
+	DropNewest OverflowPolicy = iota
+	// DropOldest discards the oldest buffered notification, to make room for the notification that has been received.
+	DropOldest
+	// Block waits until the reader is ready. Note that the session cannot process any other message, including rpc
+	// replies, while it is waiting.
+	Block
+	// Spill queues the notification in an unbounded buffer, until the reader is ready.
+	Spill
+)
+
+// DefaultSinkBufferSize defines the buffer size of a notification sink if it is not configured.
+const DefaultSinkBufferSize = 64
+
+// SinkConfig defines the properties of a notification sink.
+type SinkConfig struct {
+	// Name identifies the sink in trace events and drop counts. If empty, a name is allocated by the session.
+	Name string
+	// The event name, if any, that a notification must have to be delivered to the sink.
+	EventName string
+	// The event namespace, if any, that a notification must have to be delivered to the sink.
+	Namespace string
+	// The size of the buffer of the sink channel.
+	BufferSize int
+	// The policy applied when the buffer is full.
+	Overflow OverflowPolicy
+}
+
+// NotificationSink delivers the notifications received by a session that match its configuration.
+type NotificationSink struct {
+	cfg     SinkConfig
+	trace   *ClientTrace
+	ch      chan *common.Notification
+	done    chan struct{}
+	once    sync.Once
+	dropped uint64
+	// dLock is held while a notification is delivered, so that the channel is not closed during delivery.
+	dLock sync.Mutex
+
+	// The unbounded queue of a sink with the Spill policy, which is forwarded to ch.
+	qLock sync.Mutex
+	queue []*common.Notification
+	ready chan struct{}
+}
+
+func newNotificationSink(cfg *SinkConfig, trace *ClientTrace) *NotificationSink {
+	sink := &NotificationSink{cfg: *cfg, trace: trace, done: make(chan struct{})}
+	if sink.cfg.BufferSize <= 0 {
+		sink.cfg.BufferSize = DefaultSinkBufferSize
+	}
+	sink.ch = make(chan *common.Notification, sink.cfg.BufferSize)
+	if sink.cfg.Overflow == Spill {
+		sink.ready = make(chan struct{}, 1)
+		go sink.forward()
+	}
+	return sink
+}
+
+// Name returns the name of the sink.
+func (s *NotificationSink) Name() string {
+	return s.cfg.Name
+}
+
+// Notifications returns the channel that delivers the notifications. The channel is closed when the sink is
+// removed or the session is closed; any notifications queued by a Spill sink are then discarded.
+func (s *NotificationSink) Notifications() <-chan *common.Notification {
+	return s.ch
+}
+
+// Dropped returns the number of notifications discarded by the sink.
+func (s *NotificationSink) Dropped() uint64 {
+	return atomic.LoadUint64(&s.dropped)
+}
+
+func (s *NotificationSink) matches(n *common.Notification) bool {
+	return (s.cfg.EventName == "" || s.cfg.EventName == n.XMLName.Local) &&
+		(s.cfg.Namespace == "" || s.cfg.Namespace == n.XMLName.Space)
+}
+
+// deliver applies the overflow policy of the sink to deliver the notification, unless the sink is closed. closing
+// is closed when the session is closing, to release a blocked delivery.
+func (s *NotificationSink) deliver(n *common.Notification, closing chan struct{}) {
+	s.dLock.Lock()
+	defer s.dLock.Unlock()
+	select {
+	case <-s.done:
+		return
+	default:
+	}
+
+	switch s.cfg.Overflow {
+	case DropOldest:
+		for {
+			select {
+			case s.ch <- n:
+				return
+			default:
+			}
+			select {
+			case old := <-s.ch:
+				s.drop(old)
+			default:
+			}
+		}
+	case Block:
+		select {
+		case s.ch <- n:
+		case <-s.done:
+		case <-closing:
+		}
+	case Spill:
+		s.qLock.Lock()
+		s.queue = append(s.queue, n)
+		s.qLock.Unlock()
+		select {
+		case s.ready <- struct{}{}:
+		default:
+		}
+	default:
+		select {
+		case s.ch <- n:
+		default:
+			s.drop(n)
+		}
+	}
+}
+
+func (s *NotificationSink) drop(n *common.Notification) {
+	s.trace.NotificationSinkDropped(s.cfg.Name, n, atomic.AddUint64(&s.dropped, 1))
+}
+
+// forward delivers the notifications queued by a Spill sink, until the sink is closed.
+func (s *NotificationSink) forward() {
+	defer close(s.ch)
+	for {
+		s.qLock.Lock()
+		var n *common.Notification
+		if len(s.queue) > 0 {
+			n = s.queue[0]
+			s.queue[0] = nil
+			s.queue = s.queue[1:]
+		}
+		s.qLock.Unlock()
+
+		if n == nil {
+			select {
+			case <-s.ready:
+				continue
+			case <-s.done:
+				return
+			}
+		}
+
+		select {
+		case s.ch <- n:
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// stop signals that the sink is closing, releasing any blocked delivery.
+func (s *NotificationSink) stop() {
+	s.once.Do(func() {
+		close(s.done)
+	})
+}
+
+// close closes the sink, once any delivery in progress, which stop releases, has finished.
+func (s *NotificationSink) close() {
+	s.stop()
+	if s.cfg.Overflow != Spill {
+		s.dLock.Lock()
+		close(s.ch)
+		s.dLock.Unlock()
+	}
+}
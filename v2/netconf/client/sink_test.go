@@ -0,0 +1,163 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/damianoneill/net/v2/netconf/common"
+	"github.com/damianoneill/net/v2/netconf/testserver"
+
+	assert "github.com/stretchr/testify/require"
+)
+
+func TestNotificationSinks(t *testing.T) {
+	var traced uint64
+	ctx := WithClientTrace(context.Background(), &ClientTrace{
+		NotificationSinkDropped: func(sink string, m *common.Notification, dropped uint64) {
+			atomic.StoreUint64(&traced, dropped)
+		},
+	})
+	ts := testserver.NewTestNetconfServer(t)
+	ncs := newNCClientSessionWithContext(ctx, t, ts)
+	defer ncs.Close()
+
+	starts := ncs.AddNotificationSink(&SinkConfig{Name: "starts", EventName: "netconf-session-start", BufferSize: 1})
+	others := ncs.AddNotificationSink(&SinkConfig{Namespace: "urn:other"})
+	all := ncs.AddNotificationSink(&SinkConfig{Overflow: Spill})
+	assert.Equal(t, "sink-2", others.Name(), "Expected sink name to be allocated")
+
+	sh := ts.SessionHandler(ncs.ID())
+	sh.WaitStart()
+	for i := 0; i < 3; i++ {
+		sh.SendNotification(notificationEvent())
+	}
+	sh.SendNotification(otherEvent(1))
+	for i := 0; i < 4; i++ {
+		<-all.Notifications()
+	}
+
+	assert.Len(t, starts.Notifications(), 1, "Expected one buffered notification")
+	assert.Equal(t, uint64(2), starts.Dropped(), "Expected notifications to be dropped")
+	assert.Equal(t, uint64(2), atomic.LoadUint64(&traced), "Expected drops to be traced")
+	assert.Equal(t, map[string]uint64{"starts": 2, "sink-2": 0, "sink-3": 0}, ncs.NotificationDrops(), "Unexpected drops")
+
+	n := <-others.Notifications()
+	assert.Equal(t, "urn:other", n.XMLName.Space, "Unexpected notification")
+	assert.Len(t, others.Notifications(), 0, "Expected other notifications to be filtered")
+
+	ncs.RemoveNotificationSink(starts)
+	<-starts.Notifications()
+	_, ok := <-starts.Notifications()
+	assert.False(t, ok, "Expected removed sink to be closed")
+	assert.NotContains(t, ncs.NotificationDrops(), "starts", "Expected sink to be removed")
+}
+
+func TestNotificationSinkDropOldest(t *testing.T) {
+	ts := testserver.NewTestNetconfServer(t)
+	ncs := newNCClientSession(t, ts)
+	defer ncs.Close()
+
+	sink := ncs.AddNotificationSink(&SinkConfig{BufferSize: 2, Overflow: DropOldest})
+	all := ncs.AddNotificationSink(&SinkConfig{Overflow: Spill})
+
+	sh := ts.SessionHandler(ncs.ID())
+	sh.WaitStart()
+	for i := 1; i <= 3; i++ {
+		sh.SendNotification(otherEvent(i))
+		<-all.Notifications()
+	}
+
+	assert.Equal(t, otherEvent(2), (<-sink.Notifications()).Event, "Expected oldest notification to be dropped")
+	assert.Equal(t, otherEvent(3), (<-sink.Notifications()).Event, "Expected newest notification")
+	assert.Equal(t, uint64(1), sink.Dropped(), "Expected notification to be dropped")
+}
+
+func TestNotificationSinkBlock(t *testing.T) {
+	ts := testserver.NewTestNetconfServer(t)
+	ncs := newNCClientSession(t, ts)
+	defer ncs.Close()
+
+	sink := ncs.AddNotificationSink(&SinkConfig{BufferSize: 1, Overflow: Block})
+
+	sh := ts.SessionHandler(ncs.ID())
+	sh.WaitStart()
+	for i := 1; i <= 3; i++ {
+		sh.SendNotification(otherEvent(i))
+	}
+	for i := 1; i <= 3; i++ {
+		assert.Equal(t, otherEvent(i), (<-sink.Notifications()).Event, "Expected every notification")
+	}
+	assert.Equal(t, uint64(0), sink.Dropped(), "Not expecting notifications to be dropped")
+
+	// Removing a blocked sink must release the session.
+	sh.SendNotification(otherEvent(4)).SendNotification(otherEvent(5))
+	ncs.RemoveNotificationSink(sink)
+	for range sink.Notifications() {
+	}
+
+	reply, err := ncs.Execute(common.Request(`<get/>`))
+	assert.NoError(t, err, "Not expecting exec to fail")
+	assert.NotNil(t, reply, "Reply should be non-nil")
+}
+
+func TestNotificationSinkBlockedReaderAddsSink(t *testing.T) {
+	ts := testserver.NewTestNetconfServer(t)
+	ncs := newNCClientSession(t, ts)
+	defer ncs.Close()
+
+	spill := ncs.AddNotificationSink(&SinkConfig{Overflow: Spill})
+	block := ncs.AddNotificationSink(&SinkConfig{BufferSize: 1, Overflow: Block})
+
+	// The second notification is delivered to the spill sink, and then blocks on the full block sink.
+	sh := ts.SessionHandler(ncs.ID())
+	sh.WaitStart()
+	sh.SendNotification(otherEvent(1)).SendNotification(otherEvent(2))
+	<-spill.Notifications()
+	<-spill.Notifications()
+
+	added := make(chan *NotificationSink)
+	go func() {
+		ncs.RemoveNotificationSink(spill)
+		added <- ncs.AddNotificationSink(&SinkConfig{})
+	}()
+	select {
+	case sink := <-added:
+		assert.NotNil(t, sink, "Expected sink to be added")
+	case <-time.After(time.Second):
+		assert.Fail(t, "Not expecting sinks to be blocked by delivery")
+	}
+
+	for i := 1; i <= 2; i++ {
+		assert.Equal(t, otherEvent(i), (<-block.Notifications()).Event, "Expected every notification")
+	}
+}
+
+func TestNotificationSinkSessionClosed(t *testing.T) {
+	ts := testserver.NewTestNetconfServer(t)
+	ncs := newNCClientSession(t, ts)
+
+	spill := ncs.AddNotificationSink(&SinkConfig{Overflow: Spill})
+	block := ncs.AddNotificationSink(&SinkConfig{BufferSize: 1, Overflow: Block})
+	sh := ts.SessionHandler(ncs.ID())
+	sh.WaitStart()
+	sh.SendNotification(otherEvent(1)).SendNotification(otherEvent(2))
+	<-spill.Notifications()
+	<-spill.Notifications()
+
+	ncs.Close()
+	for range block.Notifications() {
+	}
+	for range spill.Notifications() {
+	}
+
+	sink := ncs.AddNotificationSink(&SinkConfig{})
+	_, ok := <-sink.Notifications()
+	assert.False(t, ok, "Expected sink to be closed")
+}
+
+func otherEvent(id int) string {
+	return fmt.Sprintf(`<other-event xmlns="urn:other"><id>%d</id></other-event>`, id)
+}
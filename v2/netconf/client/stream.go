@@ -0,0 +1,207 @@
+package client
+
+import (
+	"encoding/xml"
+	"io"
+	"sync"
+
+	"github.com/damianoneill/net/v2/netconf/common"
+	"github.com/damianoneill/net/v2/netconf/common/codec"
+)
+
+// ReplyStream provides streaming access to the content of an rpc-reply, as it is read from the transport.
+// It implements xml.TokenReader, delivering the tokens of the elements contained by the rpc-reply element
+// and returning io.EOF when the end of the rpc-reply is reached.
+// Any rpc-error elements in the reply are consumed by the stream, rather than delivered as tokens, and are
+// reported by Close.
+type ReplyStream struct {
+	dec *codec.Decoder
+	// Start is the rpc-reply start element.
+	Start xml.StartElement
+
+	depth   int
+	eof     bool
+	errors  []common.RPCError
+	readErr error
+
+	xdec      *xml.Decoder
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+func newReplyStream(dec *codec.Decoder, start xml.StartElement) *ReplyStream {
+	return &ReplyStream{dec: dec, Start: start, closed: make(chan struct{})}
+}
+
+// MessageID returns the message-id of the rpc-reply.
+func (rs *ReplyStream) MessageID() string {
+	return replyMessageID(rs.Start)
+}
+
+// Token returns the next token of the reply content, or io.EOF if the end of the reply has been reached.
+func (rs *ReplyStream) Token() (xml.Token, error) {
+	for {
+		if rs.eof {
+			return nil, io.EOF
+		}
+		if rs.readErr != nil {
+			return nil, rs.readErr
+		}
+
+		token, err := rs.dec.Token()
+		if err != nil {
+			rs.readErr = err
+			return nil, err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			if rs.depth == 0 && t.Name.Local == "rpc-error" {
+				rs.decodeError(&t)
+				continue
+			}
+			rs.depth++
+		case xml.EndElement:
+			if rs.depth == 0 {
+				// End of the rpc-reply element.
+				rs.eof = true
+				return nil, io.EOF
+			}
+			rs.depth--
+		}
+		return token, nil
+	}
+}
+
+// Decoder returns an xml.Decoder that reads from the stream, allowing elements of the reply content to be
+// decoded into structs with DecodeElement.
+// The same decoder is returned on each call, and tokens should not be read directly from the stream
+// once it has been used.
+func (rs *ReplyStream) Decoder() *xml.Decoder {
+	if rs.xdec == nil {
+		rs.xdec = xml.NewTokenDecoder(rs)
+	}
+	return rs.xdec
+}
+
+// WriteTo writes the remaining content of the reply to w, as XML.
+func (rs *ReplyStream) WriteTo(w io.Writer) (n int64, err error) {
+	cw := &countingWriter{w: w}
+	tw := NewTokenWriter(cw)
+	for {
+		var token xml.Token
+		if token, err = rs.Token(); err != nil {
+			break
+		}
+		if err = tw.WriteToken(token); err != nil {
+			break
+		}
+	}
+	if err == io.EOF {
+		err = tw.Flush()
+	}
+	return cw.n, err
+}
+
+// Close discards any unread content of the reply and releases the session to process further messages.
+// It returns an error if the reply could not be read, or if it contained an rpc-error with error severity.
+func (rs *ReplyStream) Close() error {
+	rs.closeOnce.Do(func() {
+		for {
+			if _, err := rs.Token(); err != nil {
+				break
+			}
+		}
+		close(rs.closed)
+	})
+
+	if rs.readErr != nil {
+		return rs.readErr
+	}
+	return mapError(&common.RPCReply{Errors: rs.errors})
+}
+
+func (rs *ReplyStream) decodeError(start *xml.StartElement) {
+	rpcErr := common.RPCError{}
+	if err := rs.dec.DecodeElement(&rpcErr, start); err != nil {
+		rs.readErr = err
+		return
+	}
+	rs.errors = append(rs.errors, rpcErr)
+}
+
+// TokenWriter writes xml tokens, such as those delivered by a ReplyStream, to an underlying writer.
+type TokenWriter struct {
+	enc *xml.Encoder
+	// spaces holds the namespaces of the currently open elements.
+	spaces []string
+}
+
+// NewTokenWriter returns a TokenWriter that writes to w.
+func NewTokenWriter(w io.Writer) *TokenWriter {
+	return &TokenWriter{enc: xml.NewEncoder(w)}
+}
+
+// WriteToken writes the token to the underlying writer.
+// A default namespace declaration is written for an element only when its namespace differs from that of
+// its parent; namespace prefix declarations are retained, so that prefixed values in the content remain
+// resolvable.
+func (tw *TokenWriter) WriteToken(token xml.Token) error {
+	switch t := token.(type) {
+	case xml.StartElement:
+		parent := tw.parentSpace()
+		tw.spaces = append(tw.spaces, t.Name.Space)
+
+		attrs := make([]xml.Attr, 0, len(t.Attr)+1)
+		for _, attr := range t.Attr {
+			switch {
+			case attr.Name.Space == "" && attr.Name.Local == "xmlns":
+				// The default namespace is declared as required, below.
+				continue
+			case attr.Name.Space == "xmlns":
+				attr.Name = xml.Name{Local: "xmlns:" + attr.Name.Local}
+			}
+			attrs = append(attrs, attr)
+		}
+		switch {
+		case t.Name.Space == parent:
+			t.Name.Space = ""
+		case t.Name.Space == "":
+			attrs = append(attrs, xml.Attr{Name: xml.Name{Local: "xmlns"}})
+		}
+		t.Attr = attrs
+		token = t
+	case xml.EndElement:
+		if len(tw.spaces) > 0 {
+			tw.spaces = tw.spaces[:len(tw.spaces)-1]
+		}
+		if t.Name.Space == tw.parentSpace() {
+			t.Name.Space = ""
+		}
+		token = t
+	}
+	return tw.enc.EncodeToken(token)
+}
+
+func (tw *TokenWriter) parentSpace() string {
+	if len(tw.spaces) == 0 {
+		return ""
+	}
+	return tw.spaces[len(tw.spaces)-1]
+}
+
+// Flush flushes any buffered output to the underlying writer.
+func (tw *TokenWriter) Flush() error {
+	return tw.enc.Flush()
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (cw *countingWriter) Write(p []byte) (n int, err error) {
+	n, err = cw.w.Write(p)
+	cw.n += int64(n)
+	return
+}
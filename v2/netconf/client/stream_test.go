@@ -0,0 +1,89 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"io"
+	"testing"
+
+	"github.com/damianoneill/net/v2/netconf/common"
+	"github.com/damianoneill/net/v2/netconf/testserver"
+
+	assert "github.com/stretchr/testify/require"
+)
+
+func TestExecuteStreamWriteTo(t *testing.T) {
+	ncs := newNCClientSession(t, testserver.NewTestNetconfServer(t))
+	defer ncs.Close()
+
+	rs, err := ncs.ExecuteStream(context.Background(),
+		common.Request(`<get><top xmlns="urn:test"><sub xmlns:p="urn:p" attr="p:value"><child/></sub></top></get>`))
+	assert.NoError(t, err, "Not expecting exec to fail")
+	assert.NotEmpty(t, rs.MessageID(), "Expected message id")
+
+	var buf bytes.Buffer
+	_, err = rs.WriteTo(&buf)
+	assert.NoError(t, err, "Not expecting write to fail")
+	assert.NoError(t, rs.Close(), "Not expecting close to fail")
+	assert.Equal(t, `<data xmlns="urn:ietf:params:xml:ns:netconf:base:1.0">`+
+		`<top xmlns="urn:test"><sub xmlns:p="urn:p" attr="p:value"><child></child></sub></top></data>`,
+		buf.String(), "Unexpected reply content")
+
+	// Session should continue to process replies after the stream is closed.
+	reply, err := ncs.Execute(common.Request(`<get><response/></get>`))
+	assert.NoError(t, err, "Not expecting exec to fail")
+	assert.Equal(t, `<data><response/></data>`, reply.Data, "Reply should contain response data")
+}
+
+func TestExecuteStreamDecoder(t *testing.T) {
+	ncs := newNCClientSession(t, testserver.NewTestNetconfServer(t))
+	defer ncs.Close()
+
+	rs, err := ncs.ExecuteStream(context.Background(),
+		common.Request(`<get><item><name>a</name></item><item><name>b</name></item></get>`))
+	assert.NoError(t, err, "Not expecting exec to fail")
+
+	type item struct {
+		Name string `xml:"name"`
+	}
+
+	var names []string
+	dec := rs.Decoder()
+	for {
+		token, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		assert.NoError(t, err, "Not expecting token read to fail")
+		if start, ok := token.(xml.StartElement); ok && start.Name.Local == "item" {
+			it := item{}
+			assert.NoError(t, dec.DecodeElement(&it, &start), "Not expecting decode to fail")
+			names = append(names, it.Name)
+		}
+	}
+	assert.NoError(t, rs.Close(), "Not expecting close to fail")
+	assert.Equal(t, []string{"a", "b"}, names, "Unexpected decoded items")
+}
+
+func TestExecuteStreamWithFailingRequest(t *testing.T) {
+	ncs := newNCClientSession(t, testserver.NewTestNetconfServer(t).WithRequestHandler(testserver.FailingRequestHandler))
+	defer ncs.Close()
+
+	rs, err := ncs.ExecuteStream(context.Background(), common.Request(`<get><response/></get>`))
+	assert.NoError(t, err, "Not expecting exec to fail")
+
+	// Discard content without reading it.
+	err = rs.Close()
+	assert.Error(t, err, "Expecting close to report rpc error")
+	assert.Equal(t, "netconf rpc [error] 'oops'", err.Error(), "Expected error")
+}
+
+func TestExecuteStreamUnfulfilled(t *testing.T) {
+	ncs := newNCClientSession(t, testserver.NewTestNetconfServer(t).WithRequestHandler(testserver.CloseRequestHandler))
+	defer ncs.Close()
+
+	rs, err := ncs.ExecuteStream(context.Background(), common.Request(`<get><response/></get>`))
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF, "Expecting exec to fail")
+	assert.Nil(t, rs, "Stream should be nil")
+}
@@ -79,11 +79,23 @@ type ClientTrace struct {
 	// NotificationDropped is called when a notification is dropped because the reader is not ready.
 	NotificationDropped func(m *common.Notification)
 
+	// NotificationSinkDropped is called when a notification sink drops a notification according to its
+	// overflow policy, with the total number of notifications dropped by the sink.
+	NotificationSinkDropped func(sink string, m *common.Notification, dropped uint64)
+
 	// ExecuteStart is called before the execution of an rpc request.
 	ExecuteStart func(req common.Request, async bool)
 
 	// ExecuteDone is called after the execution of an rpc request.
 	ExecuteDone func(req common.Request, async bool, res *common.RPCReply, err error, d time.Duration)
+
+	// ReplyDropped is called when an rpc reply is discarded, either because it does not match
+	// any outstanding request or because the requester is no longer waiting for it.
+	ReplyDropped func(res *common.RPCReply, err error)
+
+	// ConnectionStateChanged is called when the connection state of a ResilientSession changes, with err
+	// indicating the cause of any failure.
+	ConnectionStateChanged func(target string, state ConnectionState, err error)
 }
 
 // DefaultLoggingHooks provides a default logging hook to report errors.
@@ -145,12 +157,21 @@ var DiagnosticLoggingHooks = &ClientTrace{
 	NotificationDropped: func(n *common.Notification) {
 		log.Printf("NETCONF-NotificationDropped %s\n", n.XMLName.Local)
 	},
+	NotificationSinkDropped: func(sink string, n *common.Notification, dropped uint64) {
+		log.Printf("NETCONF-NotificationSinkDropped sink:%s %s dropped:%d\n", sink, n.XMLName.Local, dropped)
+	},
 	ExecuteStart: func(req common.Request, async bool) {
 		log.Printf("NETCONF-ExecuteStart async:%v req:%s\n", async, req)
 	},
 	ExecuteDone: func(req common.Request, async bool, res *common.RPCReply, err error, d time.Duration) {
 		log.Printf("NETCONF-ExecuteDone async:%v req:%s err:%v took:%dms\n", async, req, err, d.Milliseconds())
 	},
+	ReplyDropped: func(res *common.RPCReply, err error) {
+		log.Printf("NETCONF-ReplyDropped message-id:%s err:%v\n", res.MessageID, err)
+	},
+	ConnectionStateChanged: func(target string, state ConnectionState, err error) {
+		log.Printf("NETCONF-ConnectionStateChanged target:%s state:%s err:%v\n", target, state, err)
+	},
 }
 
 // NoOpLoggingHooks provides set of hooks that do nothing.
@@ -172,4 +193,9 @@ var NoOpLoggingHooks = &ClientTrace{
 	NotificationDropped:  func(n *common.Notification) {},
 	ExecuteStart:         func(req common.Request, async bool) {},
 	ExecuteDone:          func(req common.Request, async bool, res *common.RPCReply, err error, d time.Duration) {},
+	ReplyDropped:         func(res *common.RPCReply, err error) {},
+
+	NotificationSinkDropped: func(sink string, n *common.Notification, dropped uint64) {},
+
+	ConnectionStateChanged: func(target string, state ConnectionState, err error) {},
 }
@@ -0,0 +1,162 @@
+package common
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Defines a model of the capabilities advertised in a hello message.
+
+// Define the URN prefixes of NETCONF capabilities.
+const (
+	BaseCapabilityPrefix = "urn:ietf:params:netconf:base:"
+	CapabilityPrefix     = "urn:ietf:params:netconf:capability:"
+)
+
+// Capability describes a capability advertised by a peer: either a NETCONF base or optional capability, such as
+// CapCandidate, or a YANG module advertisement (RFC 6020 section 5.6.4).
+type Capability struct {
+	// The capability URI, without its parameters.
+	URI string
+	// The parameters of the capability, such as module, revision, features, deviations or basic-mode.
+	Params url.Values
+}
+
+// ParseCapability parses a capability URI and its query parameters.
+func ParseCapability(s string) (*Capability, error) {
+	uri, query, _ := strings.Cut(strings.TrimSpace(s), "?")
+	params, err := url.ParseQuery(query)
+	if err != nil {
+		return nil, fmt.Errorf("invalid capability %q: %w", s, err)
+	}
+	return &Capability{URI: uri, Params: params}, nil
+}
+
+// IsBase reports whether the capability identifies a version of the NETCONF base protocol.
+func (c *Capability) IsBase() bool {
+	return strings.HasPrefix(c.URI, BaseCapabilityPrefix)
+}
+
+// Name returns the name of a NETCONF capability, such as "candidate", or "base" for a base protocol capability.
+// An empty string is returned if the capability is not a NETCONF capability.
+func (c *Capability) Name() string {
+	if c.IsBase() {
+		return "base"
+	}
+	if rest := strings.TrimPrefix(c.URI, CapabilityPrefix); rest != c.URI {
+		name, _, _ := strings.Cut(rest, ":")
+		return name
+	}
+	return ""
+}
+
+// Version returns the version of a NETCONF capability, such as "1.1", or an empty string if the capability is not
+// a NETCONF capability.
+func (c *Capability) Version() string {
+	if c.Name() == "" {
+		return ""
+	}
+	return c.URI[strings.LastIndexByte(c.URI, ':')+1:]
+}
+
+// Module returns the YANG module advertised by the capability, or nil if the capability is not a module
+// advertisement.
+func (c *Capability) Module() *Module {
+	name := c.Params.Get("module")
+	if name == "" {
+		return nil
+	}
+	return &Module{
+		Name:       name,
+		Namespace:  c.URI,
+		Revision:   c.Params.Get("revision"),
+		Features:   splitParam(c.Params.Get("features")),
+		Deviations: splitParam(c.Params.Get("deviations")),
+	}
+}
+
+// Module describes a YANG module advertised as a capability.
+type Module struct {
+	Name       string
+	Namespace  string
+	Revision   string
+	Features   []string
+	Deviations []string
+}
+
+// HasFeature reports whether the module advertisement includes the feature.
+func (m *Module) HasFeature(feature string) bool {
+	for _, f := range m.Features {
+		if f == feature {
+			return true
+		}
+	}
+	return false
+}
+
+// Capabilities defines the set of capabilities advertised by a peer.
+type Capabilities []*Capability
+
+// ParseCapabilities parses the capabilities advertised by a peer, such as those returned by a session's
+// ServerCapabilities. Capabilities that cannot be parsed are ignored.
+func ParseCapabilities(caps []string) Capabilities {
+	result := make(Capabilities, 0, len(caps))
+	for _, s := range caps {
+		if c, err := ParseCapability(s); err == nil {
+			result = append(result, c)
+		}
+	}
+	return result
+}
+
+// Get returns the capability identified by uri, ignoring capability parameters, or nil if it is not present.
+func (cs Capabilities) Get(uri string) *Capability {
+	for _, c := range cs {
+		if c.URI == uri {
+			return c
+		}
+	}
+	return nil
+}
+
+// HasCapability reports whether any of the capabilities identified by uris are present, ignoring capability
+// parameters.
+func (cs Capabilities) HasCapability(uris ...string) bool {
+	for _, uri := range uris {
+		if cs.Get(uri) != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// Module returns the advertisement of the named YANG module, or nil if the module is not advertised.
+// Note that servers that implement YANG 1.1 advertise their modules through the YANG library, rather than as
+// capabilities.
+func (cs Capabilities) Module(name string) *Module {
+	for _, c := range cs {
+		if m := c.Module(); m != nil && m.Name == name {
+			return m
+		}
+	}
+	return nil
+}
+
+// Modules returns the YANG modules advertised as capabilities.
+func (cs Capabilities) Modules() []*Module {
+	var modules []*Module
+	for _, c := range cs {
+		if m := c.Module(); m != nil {
+			modules = append(modules, m)
+		}
+	}
+	return modules
+}
+
+func splitParam(value string) []string {
+	if value == "" {
+		return nil
+	}
+	return strings.Split(value, ",")
+}
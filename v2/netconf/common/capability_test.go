@@ -0,0 +1,59 @@
+package common
+
+import (
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+)
+
+func TestParseCapability(t *testing.T) {
+	c, err := ParseCapability(CapBase11)
+	assert.NoError(t, err, "Not expecting parse to fail")
+	assert.True(t, c.IsBase(), "Expected base capability")
+	assert.Equal(t, "base", c.Name(), "Unexpected name")
+	assert.Equal(t, "1.1", c.Version(), "Unexpected version")
+	assert.Nil(t, c.Module(), "Not expecting module")
+
+	c, err = ParseCapability("urn:ietf:params:netconf:capability:with-defaults:1.0?basic-mode=explicit&also-supported=trim,report-all")
+	assert.NoError(t, err, "Not expecting parse to fail")
+	assert.Equal(t, "with-defaults", c.Name(), "Unexpected name")
+	assert.Equal(t, "1.0", c.Version(), "Unexpected version")
+	assert.Equal(t, "explicit", c.Params.Get("basic-mode"), "Unexpected parameter")
+
+	c, err = ParseCapability("urn:ietf:params:xml:ns:yang:ietf-interfaces?module=ietf-interfaces&revision=2018-02-20" +
+		"&features=arbitrary-names,pre-provisioning&deviations=vendor-deviations")
+	assert.NoError(t, err, "Not expecting parse to fail")
+	assert.Equal(t, "", c.Name(), "Not expecting name")
+	assert.Equal(t, "", c.Version(), "Not expecting version")
+	assert.Equal(t, &Module{
+		Name:       "ietf-interfaces",
+		Namespace:  "urn:ietf:params:xml:ns:yang:ietf-interfaces",
+		Revision:   "2018-02-20",
+		Features:   []string{"arbitrary-names", "pre-provisioning"},
+		Deviations: []string{"vendor-deviations"},
+	}, c.Module(), "Unexpected module")
+	assert.True(t, c.Module().HasFeature("pre-provisioning"), "Expected feature")
+	assert.False(t, c.Module().HasFeature("if-mib"), "Not expecting feature")
+
+	_, err = ParseCapability("urn:example?module=%zz")
+	assert.Error(t, err, "Expecting parse to fail")
+}
+
+func TestParseCapabilities(t *testing.T) {
+	caps := ParseCapabilities([]string{
+		CapBase10,
+		CapCandidate,
+		"urn:example?module=%zz",
+		"urn:ietf:params:xml:ns:yang:ietf-interfaces?module=ietf-interfaces&revision=2018-02-20",
+		"urn:example:system?module=example-system",
+	})
+	assert.Len(t, caps, 4, "Expected invalid capability to be ignored")
+	assert.True(t, caps.HasCapability(CapCandidate), "Expected capability")
+	assert.True(t, caps.HasCapability(CapValidate11, CapBase10), "Expected capability")
+	assert.False(t, caps.HasCapability(CapWritableRunning), "Not expecting capability")
+	assert.Nil(t, caps.Get(CapStartup), "Not expecting capability")
+
+	assert.Equal(t, "2018-02-20", caps.Module("ietf-interfaces").Revision, "Unexpected module revision")
+	assert.Nil(t, caps.Module("ietf-system"), "Not expecting module")
+	assert.Len(t, caps.Modules(), 2, "Unexpected modules")
+}
@@ -1,7 +1,9 @@
 package codec
 
 import (
+	"bytes"
 	"encoding/xml"
+	"errors"
 	"io"
 
 	"github.com/damianoneill/net/v2/netconf/common/codec/rfc6242"
@@ -36,6 +38,87 @@ func (e *Encoder) Encode(msg interface{}) error {
 	return e.ncEncoder.EndOfMessage()
 }
 
+// EncodeStream encodes a netconf message whose marshalled form contains the marker string, replacing the
+// marker with content copied from r.
+// Only the message envelope is buffered; the content is copied to the transport as it is read.
+// If r fails to deliver the content, the message is still terminated, so that message framing is
+// preserved, but only after abortedContent has been written, so that the server rejects the message rather
+// than act on partial content; a *ContentError holding the read error is returned.
+func (e *Encoder) EncodeStream(msg interface{}, marker string, r io.Reader) error {
+	var envelope bytes.Buffer
+	if err := xml.NewEncoder(&envelope).Encode(msg); err != nil {
+		return err
+	}
+
+	head, tail, found := bytes.Cut(envelope.Bytes(), []byte(marker))
+	if !found {
+		return errStreamMarkerNotFound
+	}
+
+	_, err := e.ncEncoder.Write(append([]byte(xml.Header), head...))
+	if err != nil {
+		return err
+	}
+
+	rr := &readErrRecorder{Reader: r}
+	if _, err = io.Copy(e.ncEncoder, rr); err != nil && rr.err == nil {
+		// The transport write failed, so the message cannot be terminated.
+		return err
+	}
+	if rr.err != nil {
+		if _, werr := e.ncEncoder.Write([]byte(abortedContent)); werr != nil {
+			return werr
+		}
+	}
+
+	if _, werr := e.ncEncoder.Write(tail); werr != nil {
+		return werr
+	}
+	if werr := e.ncEncoder.EndOfMessage(); werr != nil {
+		return werr
+	}
+	if rr.err != nil {
+		return &ContentError{Err: rr.err}
+	}
+	return nil
+}
+
+// abortedContent is written after the content copied from a failed reader. It opens an element that is never
+// closed, which makes the message malformed whatever content preceded it: it is illegal within a tag, an
+// attribute value or a reference, it leaves any comment, CDATA section or processing instruction unterminated,
+// and otherwise it mismatches the end tags of the message.
+const abortedContent = "<stream-content-aborted>"
+
+// ContentError is returned by EncodeStream when the content reader fails. The message has been sent, but in a
+// malformed form that the server will reject.
+type ContentError struct {
+	Err error
+}
+
+func (e *ContentError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ContentError) Unwrap() error {
+	return e.Err
+}
+
+var errStreamMarkerNotFound = errors.New("stream content marker not found in message")
+
+// readErrRecorder records any error returned by the underlying reader.
+type readErrRecorder struct {
+	io.Reader
+	err error
+}
+
+func (r *readErrRecorder) Read(p []byte) (n int, err error) {
+	n, err = r.Reader.Read(p)
+	if err != nil && err != io.EOF {
+		r.err = err
+	}
+	return
+}
+
 // NewDecoder delivers a new decoder.
 func NewDecoder(t io.Reader) *Decoder {
 	ncDecoder := rfc6242.NewDecoder(t)
@@ -1,8 +1,14 @@
 package codec
 
 import (
+	"bytes"
+	"encoding/xml"
 	"errors"
+	"fmt"
+	"io"
+	"strings"
 	"testing"
+	"testing/iotest"
 
 	"github.com/damianoneill/net/netconf/mocks"
 	"github.com/stretchr/testify/mock"
@@ -32,6 +38,65 @@ func TestEncoderFailures(t *testing.T) {
 	assert.Error(t, err, "Expect failure")
 }
 
+type testStream struct {
+	XMLName xml.Name `xml:"stream"`
+	Content string   `xml:",innerxml"`
+}
+
+func TestEncodeStream(t *testing.T) {
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf)
+	EnableChunkedFraming(NewDecoder(nil), enc)
+
+	err := enc.EncodeStream(&testStream{Content: "<!--marker-->"}, "<!--marker-->", strings.NewReader("<big/>"))
+	assert.NoError(t, err, "Not expecting encode to fail")
+	assert.Equal(t, "\n#"+fmt.Sprint(len(xml.Header)+len("<stream>"))+"\n"+xml.Header+"<stream>"+
+		"\n#6\n<big/>"+
+		"\n#9\n</stream>"+
+		"\n##\n", buf.String(), "Unexpected encoding")
+}
+
+func TestEncodeStreamReaderFailure(t *testing.T) {
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf)
+
+	r := io.MultiReader(strings.NewReader("<partial>"), iotest.ErrReader(errors.New("failed")))
+	err := enc.EncodeStream(&testStream{Content: "<!--marker-->"}, "<!--marker-->", r)
+	assert.EqualError(t, err, "failed", "Expecting read failure")
+	var cerr *ContentError
+	assert.ErrorAs(t, err, &cerr, "Expecting content error")
+	assert.Equal(t, xml.Header+"<stream><partial><stream-content-aborted></stream>]]>]]>", buf.String(),
+		"Message should be terminated")
+}
+
+func TestEncodeStreamReaderFailureMalformsMessage(t *testing.T) {
+	for _, content := range []string{`<a/>`, `<a>text`, `<a x="1`, `<a`, `<a>&amp`, `<!-- comment`, `<![CDATA[data`, `<?pi`} {
+		var buf bytes.Buffer
+		enc := NewEncoder(&buf)
+		r := io.MultiReader(strings.NewReader(content), iotest.ErrReader(errors.New("failed")))
+		err := enc.EncodeStream(&testStream{Content: "<!--marker-->"}, "<!--marker-->", r)
+		assert.Error(t, err, "Expecting read failure")
+
+		msg := strings.TrimSuffix(buf.String(), "]]>]]>")
+		d := xml.NewDecoder(strings.NewReader(msg))
+		var derr error
+		for derr == nil {
+			_, derr = d.Token()
+		}
+		var serr *xml.SyntaxError
+		assert.ErrorAs(t, derr, &serr, "Expecting message with content %q to be malformed", content)
+	}
+}
+
+func TestEncodeStreamMissingMarker(t *testing.T) {
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf)
+
+	err := enc.EncodeStream(&testStream{}, "<!--marker-->", strings.NewReader("<big/>"))
+	assert.Error(t, err, "Expecting encode to fail")
+	assert.Empty(t, buf.String(), "Nothing should be written")
+}
+
 func TestEnableChunkedFraming(t *testing.T) {
 	enc := NewEncoder(nil)
 	dec := NewDecoder(nil)
@@ -2,7 +2,11 @@ package common
 
 import (
 	"encoding/xml"
+	"errors"
 	"fmt"
+	"io"
+	"strings"
+	"time"
 )
 
 // Defines structs representing netconf messages and notifications.
@@ -10,6 +14,18 @@ import (
 // Request represents the body of a Netconf RPC request.
 type Request interface{}
 
+// StreamingRequest is implemented by requests with content that is to be copied from a reader as the request
+// is encoded, rather than being marshalled with the request.
+// The marshalled request should contain StreamContentMarker at the position where the content is to be written.
+type StreamingRequest interface {
+	// ContentReader returns the reader that delivers the request content, or nil if the request has no
+	// streamed content.
+	ContentReader() io.Reader
+}
+
+// StreamContentMarker marks the position of streamed content in a marshalled StreamingRequest.
+const StreamContentMarker = "<!--netconf:stream-content-->"
+
 // HelloMessage defines the message sent/received during session negotiation.
 type HelloMessage struct {
 	XMLName      xml.Name `xml:"urn:ietf:params:xml:ns:netconf:base:1.0 hello"`
@@ -34,26 +50,169 @@ type RPCReply struct {
 	MessageID string     `xml:"message-id,attr"`
 }
 
+// Warnings returns the rpc-errors in the reply that have warning severity.
+func (r *RPCReply) Warnings() []RPCError {
+	return r.errorsWithSeverity("warning")
+}
+
+func (r *RPCReply) errorsWithSeverity(severity string) (errs []RPCError) {
+	for i := range r.Errors {
+		if r.Errors[i].Severity == severity {
+			errs = append(errs, r.Errors[i])
+		}
+	}
+	return
+}
+
 // RPCError defines an error reply to a RPC request
 type RPCError struct {
-	Type     string `xml:"error-type"`
-	Tag      string `xml:"error-tag"`
-	Severity string `xml:"error-severity"`
-	Path     string `xml:"error-path"`
-	Message  string `xml:"error-message"`
-	Info     string `xml:",innerxml"`
+	Type      string     `xml:"error-type"`
+	Tag       string     `xml:"error-tag"`
+	Severity  string     `xml:"error-severity"`
+	AppTag    string     `xml:"error-app-tag,omitempty"`
+	Path      string     `xml:"error-path"`
+	Message   string     `xml:"error-message"`
+	ErrorInfo *ErrorInfo `xml:"error-info,omitempty"`
+	// Info holds the unparsed content of the rpc-error element.
+	Info string `xml:",innerxml"`
 }
 
 // Error generates a string representation of the RPC error
-func (re *RPCError) Error() string {
+func (re RPCError) Error() string { //nolint:gocritic
 	return fmt.Sprintf("netconf rpc [%s] '%s'", re.Severity, re.Message)
 }
 
+// Is reports whether the error matches target, which must be an RPCError or *RPCError, such as one of
+// the netconferrors values.
+// Errors are matched by tag and type; empty fields in target match any value.
+func (re RPCError) Is(target error) bool { //nolint:gocritic
+	var t RPCError
+	switch v := target.(type) {
+	case RPCError:
+		t = v
+	case *RPCError:
+		t = *v
+	default:
+		return false
+	}
+	return (t.Tag == "" || t.Tag == re.Tag) && (t.Type == "" || t.Type == re.Type)
+}
+
+// ErrorInfo defines the error-info content of an rpc-error, as described in RFC 6241 Appendix A.
+type ErrorInfo struct {
+	// The name of the attribute that caused the error.
+	BadAttribute string `xml:"bad-attribute,omitempty"`
+	// The name of the element that caused the error.
+	BadElement string `xml:"bad-element,omitempty"`
+	// The namespace that caused the error.
+	BadNamespace string `xml:"bad-namespace,omitempty"`
+	// The session id of the session holding a lock, or zero if the lock is held by a non-NETCONF entity.
+	SessionID uint64 `xml:"session-id,omitempty"`
+	// The elements processed successfully, failed, or not processed, when continue-on-error was requested.
+	OkElements   []string `xml:"ok-element,omitempty"`
+	ErrElements  []string `xml:"err-element,omitempty"`
+	NoopElements []string `xml:"noop-element,omitempty"`
+	// Any other, typically vendor-specific, elements.
+	Extensions []RawElement `xml:",any"`
+}
+
+// RawElement holds an XML element as undecoded XML.
+type RawElement struct {
+	XMLName xml.Name
+	Attrs   []xml.Attr `xml:",any,attr"`
+	Content string     `xml:",innerxml"`
+}
+
+// RPCErrors is the error returned for an rpc-reply that contains rpc-errors with error severity.
+// It carries every rpc-error in the reply, with warnings held separately.
+type RPCErrors struct {
+	Errors   []RPCError
+	Warnings []RPCError
+}
+
+// NewRPCErrors returns the RPCErrors describing the rpc-errors in the reply, or nil if there are none with
+// error severity.
+func NewRPCErrors(r *RPCReply) *RPCErrors {
+	errs := r.errorsWithSeverity("error")
+	if len(errs) == 0 {
+		return nil
+	}
+	return &RPCErrors{Errors: errs, Warnings: r.Warnings()}
+}
+
+// Error generates a string representation of the RPC errors.
+func (re *RPCErrors) Error() string {
+	msgs := make([]string, len(re.Errors))
+	for i := range re.Errors {
+		msgs[i] = re.Errors[i].Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Is reports whether any of the errors matches target.
+func (re *RPCErrors) Is(target error) bool {
+	for i := range re.Errors {
+		if errors.Is(&re.Errors[i], target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As finds the first of the errors that matches target.
+func (re *RPCErrors) As(target interface{}) bool {
+	for i := range re.Errors {
+		if errors.As(&re.Errors[i], target) {
+			return true
+		}
+	}
+	return false
+}
+
+// LockHolder returns the session id, from the error-info of a lock-denied rpc-error in err, of the session that
+// holds the lock; it is zero if the lock is held by a non-NETCONF entity.
+// ok is false if err does not contain a lock-denied rpc-error.
+func LockHolder(err error) (sessionID uint64, ok bool) {
+	var rpcErrs *RPCErrors
+	if errors.As(err, &rpcErrs) {
+		for i := range rpcErrs.Errors {
+			if id, ok := rpcErrs.Errors[i].lockHolder(); ok {
+				return id, true
+			}
+		}
+		return 0, false
+	}
+	var rpcErr *RPCError
+	if errors.As(err, &rpcErr) {
+		return rpcErr.lockHolder()
+	}
+	return 0, false
+}
+
+func (re *RPCError) lockHolder() (uint64, bool) {
+	if re.Tag != "lock-denied" {
+		return 0, false
+	}
+	if re.ErrorInfo == nil {
+		return 0, true
+	}
+	return re.ErrorInfo.SessionID, true
+}
+
 // Notification defines a specific notification event.
 type Notification struct {
-	XMLName   xml.Name
-	EventTime string
-	Event     string `xml:",innerxml"`
+	XMLName xml.Name
+	// The time at which the event was generated, or zero if the server sent an invalid event time.
+	EventTime time.Time `xml:"-"`
+	// The event time, as sent by the server.
+	RawEventTime string `xml:"-"`
+	// The XML of the event element, as sent by the server.
+	Event string `xml:",innerxml"`
+}
+
+// Decode unmarshals the event XML into v.
+func (n *Notification) Decode(v interface{}) error {
+	return xml.Unmarshal([]byte(n.Event), v)
 }
 
 // NotificationMessage defines the notification message sent from the server.
@@ -61,6 +220,8 @@ type NotificationMessage struct {
 	XMLName   xml.Name     // `xml:"notification"`
 	EventTime string       `xml:"eventTime"`
 	Event     Notification `xml:",any"`
+	// The raw content of the notification message.
+	Content string `xml:",innerxml"`
 }
 
 type Union struct {
@@ -105,6 +266,19 @@ const (
 	CapBase10       = "urn:ietf:params:netconf:base:1.0"
 	CapBase11       = "urn:ietf:params:netconf:base:1.1"
 	CapXpath        = "urn:ietf:params:netconf:capability:xpath:1.0"
+
+	CapWritableRunning   = "urn:ietf:params:netconf:capability:writable-running:1.0"
+	CapCandidate         = "urn:ietf:params:netconf:capability:candidate:1.0"
+	CapConfirmedCommit10 = "urn:ietf:params:netconf:capability:confirmed-commit:1.0"
+	CapConfirmedCommit11 = "urn:ietf:params:netconf:capability:confirmed-commit:1.1"
+	CapRollbackOnError   = "urn:ietf:params:netconf:capability:rollback-on-error:1.0"
+	CapValidate10        = "urn:ietf:params:netconf:capability:validate:1.0"
+	CapValidate11        = "urn:ietf:params:netconf:capability:validate:1.1"
+	CapStartup           = "urn:ietf:params:netconf:capability:startup:1.0"
+	CapURL               = "urn:ietf:params:netconf:capability:url:1.0"
+	CapYangLibrary10     = "urn:ietf:params:netconf:capability:yang-library:1.0"
+	CapYangLibrary11     = "urn:ietf:params:netconf:capability:yang-library:1.1"
+	CapPartialLock       = "urn:ietf:params:netconf:capability:partial-lock:1.0"
 )
 
 // PeerSupportsChunkedFraming returns true if capability list indicates support for chunked framing.
@@ -1,6 +1,9 @@
 package common
 
 import (
+	"encoding/xml"
+	"errors"
+	"fmt"
 	"testing"
 
 	assert "github.com/stretchr/testify/require"
@@ -15,6 +18,101 @@ func TestRPCErrorString(t *testing.T) {
 	assert.Equal(t, "netconf rpc [Severity] 'Message'", err.Error())
 }
 
+func TestRPCErrorDecode(t *testing.T) {
+	reply := &RPCReply{}
+	err := xml.Unmarshal([]byte(`<rpc-reply xmlns="urn:ietf:params:xml:ns:netconf:base:1.0" message-id="1">`+
+		`<rpc-error>`+
+		`<error-type>protocol</error-type><error-tag>bad-attribute</error-tag><error-severity>error</error-severity>`+
+		`<error-app-tag>app</error-app-tag><error-path>/a/b</error-path><error-message xml:lang="en">bad</error-message>`+
+		`<error-info><bad-attribute>attr</bad-attribute><bad-element>b</bad-element><session-id>7</session-id>`+
+		`<ok-element>x</ok-element><ok-element>y</ok-element><err-element>z</err-element>`+
+		`<detail xmlns="urn:vendor" code="12"><reason>full</reason></detail></error-info>`+
+		`</rpc-error>`+
+		`<rpc-error><error-type>application</error-type><error-tag>operation-failed</error-tag>`+
+		`<error-severity>warning</error-severity></rpc-error>`+
+		`</rpc-reply>`), reply)
+	assert.NoError(t, err)
+	assert.Len(t, reply.Errors, 2)
+
+	re := reply.Errors[0]
+	assert.Equal(t, "app", re.AppTag)
+	assert.Equal(t, "/a/b", re.Path)
+	assert.Equal(t, "bad", re.Message)
+	assert.Contains(t, re.Info, "<error-info>")
+
+	info := re.ErrorInfo
+	assert.Equal(t, "attr", info.BadAttribute)
+	assert.Equal(t, "b", info.BadElement)
+	assert.Equal(t, uint64(7), info.SessionID)
+	assert.Equal(t, []string{"x", "y"}, info.OkElements)
+	assert.Equal(t, []string{"z"}, info.ErrElements)
+	assert.Len(t, info.Extensions, 1)
+	assert.Equal(t, xml.Name{Space: "urn:vendor", Local: "detail"}, info.Extensions[0].XMLName)
+	assert.Equal(t, "<reason>full</reason>", info.Extensions[0].Content)
+
+	warnings := reply.Warnings()
+	assert.Len(t, warnings, 1)
+	assert.Equal(t, "operation-failed", warnings[0].Tag)
+
+	rpcErrs := NewRPCErrors(reply)
+	assert.Equal(t, []RPCError{re}, rpcErrs.Errors)
+	assert.Equal(t, warnings, rpcErrs.Warnings)
+	assert.Nil(t, NewRPCErrors(&RPCReply{Errors: warnings}), "Not expecting warnings to be errors")
+}
+
+func TestRPCErrorIs(t *testing.T) {
+	lockDenied := RPCError{Type: "protocol", Tag: "lock-denied", Severity: "error"}
+	err := &RPCError{Type: "protocol", Tag: "lock-denied", Severity: "error", Message: "locked"}
+
+	assert.True(t, errors.Is(err, lockDenied))
+	assert.True(t, errors.Is(err, &lockDenied))
+	assert.True(t, errors.Is(err, RPCError{Tag: "lock-denied"}))
+	assert.False(t, errors.Is(err, RPCError{Type: "application", Tag: "lock-denied"}))
+	assert.False(t, errors.Is(err, RPCError{Tag: "in-use"}))
+	assert.False(t, errors.Is(err, errors.New("lock-denied")))
+
+	multi := &RPCErrors{Errors: []RPCError{{Tag: "in-use"}, *err}}
+	assert.True(t, errors.Is(multi, lockDenied))
+	assert.False(t, errors.Is(multi, RPCError{Tag: "data-exists"}))
+}
+
+func TestLockHolder(t *testing.T) {
+	lockDenied := RPCError{Type: "protocol", Tag: "lock-denied", Severity: "error", ErrorInfo: &ErrorInfo{SessionID: 12}}
+
+	id, ok := LockHolder(&RPCErrors{Errors: []RPCError{{Tag: "in-use"}, lockDenied}})
+	assert.True(t, ok, "Expecting lock holder")
+	assert.Equal(t, uint64(12), id, "Unexpected lock holder")
+
+	id, ok = LockHolder(fmt.Errorf("lock failed: %w", &lockDenied))
+	assert.True(t, ok, "Expecting lock holder")
+	assert.Equal(t, uint64(12), id, "Unexpected lock holder")
+
+	id, ok = LockHolder(&RPCError{Tag: "lock-denied"})
+	assert.True(t, ok, "Expecting lock held by a non-NETCONF entity")
+	assert.Zero(t, id, "Unexpected lock holder")
+
+	_, ok = LockHolder(&RPCErrors{Errors: []RPCError{{Tag: "in-use", ErrorInfo: &ErrorInfo{SessionID: 3}}}})
+	assert.False(t, ok, "Not expecting lock holder")
+	_, ok = LockHolder(errors.New("failed"))
+	assert.False(t, ok, "Not expecting lock holder")
+}
+
+func TestNotificationDecode(t *testing.T) {
+	n := &Notification{Event: `<event xmlns="urn:test" level="2"><id>7</id></event>`}
+	event := &struct {
+		XMLName xml.Name `xml:"urn:test event"`
+		Level   int      `xml:"level,attr"`
+		ID      int      `xml:"id"`
+	}{}
+	assert.NoError(t, n.Decode(event), "Not expecting decode to fail")
+	assert.Equal(t, 2, event.Level, "Unexpected attribute")
+	assert.Equal(t, 7, event.ID, "Unexpected element")
+
+	assert.Error(t, n.Decode(&struct {
+		XMLName xml.Name `xml:"urn:other event"`
+	}{}), "Expecting decode to fail")
+}
+
 func TestPeerSupportsChunkedFraming(t *testing.T) {
 	assert.False(t, PeerSupportsChunkedFraming([]string{NetconfNS, NetconfNotifyNS, CapBase10}))
 	assert.True(t, PeerSupportsChunkedFraming([]string{NetconfNS, NetconfNotifyNS, CapBase11}))
@@ -19,29 +19,31 @@ const (
 
 // Error tag values as defined in RFC 6241 Appendix A (unexported).
 const (
-	errTagInUse               = "in-use"
-	errTagInvalidValue        = "invalid-value"
-	errTagTooBig              = "too-big"
-	errTagMissingAttribute    = "missing-attribute"
-	errTagBadAttribute        = "bad-attribute"
-	errTagUnknownAttribute    = "unknown-attribute"
-	errTagMissingElement      = "missing-element"
-	errTagBadElement          = "bad-element"
-	errTagUnknownElement      = "unknown-element"
-	errTagUnknownNamespace    = "unknown-namespace"
-	errTagAccessDenied        = "access-denied"
-	errTagLockDenied          = "lock-denied"
-	errTagResourceDenied      = "resource-denied"
-	errTagRollbackFailed      = "rollback-failed"
-	errTagDataExists          = "data-exists"
-	errTagDataMissing         = "data-missing"
+	errTagInUse                 = "in-use"
+	errTagInvalidValue          = "invalid-value"
+	errTagTooBig                = "too-big"
+	errTagMissingAttribute      = "missing-attribute"
+	errTagBadAttribute          = "bad-attribute"
+	errTagUnknownAttribute      = "unknown-attribute"
+	errTagMissingElement        = "missing-element"
+	errTagBadElement            = "bad-element"
+	errTagUnknownElement        = "unknown-element"
+	errTagUnknownNamespace      = "unknown-namespace"
+	errTagAccessDenied          = "access-denied"
+	errTagLockDenied            = "lock-denied"
+	errTagResourceDenied        = "resource-denied"
+	errTagRollbackFailed        = "rollback-failed"
+	errTagDataExists            = "data-exists"
+	errTagDataMissing           = "data-missing"
 	errTagOperationNotSupported = "operation-not-supported"
-	errTagOperationFailed     = "operation-failed"
-	errTagMalformedMessage    = "malformed-message"
+	errTagOperationFailed       = "operation-failed"
+	errTagMalformedMessage      = "malformed-message"
 )
 
 // Pre-built RPCError values for common error conditions.
 // Use these as templates; copy and set the Message field as needed.
+// They can also be used with errors.Is to test an error returned by a client session, which matches by
+// error tag and type, for example errors.Is(err, netconferrors.ErrLockDenied).
 var (
 	// ErrInUse indicates the request requires a resource that is already in use.
 	ErrInUse = common.RPCError{Type: errorTypeProtocol, Tag: errTagInUse, Severity: severityError}
@@ -112,3 +114,15 @@ func WithPath(err common.RPCError, path string) common.RPCError {
 	err.Path = path
 	return err
 }
+
+// WithAppTag returns a copy of the error with the specified error-app-tag.
+func WithAppTag(err common.RPCError, appTag string) common.RPCError {
+	err.AppTag = appTag
+	return err
+}
+
+// WithErrorInfo returns a copy of the error with the specified error-info.
+func WithErrorInfo(err common.RPCError, info *common.ErrorInfo) common.RPCError {
+	err.ErrorInfo = info
+	return err
+}
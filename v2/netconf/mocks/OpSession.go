@@ -3,6 +3,9 @@
 package mocks
 
 import (
+	context "context"
+
+	client "github.com/damianoneill/net/v2/netconf/client"
 	common "github.com/damianoneill/net/v2/netconf/common"
 	mock "github.com/stretchr/testify/mock"
 )
@@ -12,6 +15,22 @@ type OpSession struct {
 	mock.Mock
 }
 
+// AddNotificationSink provides a mock function with given fields: cfg
+func (_m *OpSession) AddNotificationSink(cfg *client.SinkConfig) *client.NotificationSink {
+	ret := _m.Called(cfg)
+
+	var r0 *client.NotificationSink
+	if rf, ok := ret.Get(0).(func(*client.SinkConfig) *client.NotificationSink); ok {
+		r0 = rf(cfg)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*client.NotificationSink)
+		}
+	}
+
+	return r0
+}
+
 // Close provides a mock function with given fields:
 func (_m *OpSession) Close() {
 	_m.Called()
@@ -54,6 +73,66 @@ func (_m *OpSession) ExecuteAsync(req common.Request, rchan chan *common.RPCRepl
 	return r0
 }
 
+// ExecuteAsyncContext provides a mock function with given fields: ctx, req, rchan
+func (_m *OpSession) ExecuteAsyncContext(ctx context.Context, req common.Request, rchan chan *common.RPCReply) error {
+	ret := _m.Called(ctx, req, rchan)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, common.Request, chan *common.RPCReply) error); ok {
+		r0 = rf(ctx, req, rchan)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ExecuteContext provides a mock function with given fields: ctx, req
+func (_m *OpSession) ExecuteContext(ctx context.Context, req common.Request) (*common.RPCReply, error) {
+	ret := _m.Called(ctx, req)
+
+	var r0 *common.RPCReply
+	if rf, ok := ret.Get(0).(func(context.Context, common.Request) *common.RPCReply); ok {
+		r0 = rf(ctx, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*common.RPCReply)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, common.Request) error); ok {
+		r1 = rf(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ExecuteStream provides a mock function with given fields: ctx, req
+func (_m *OpSession) ExecuteStream(ctx context.Context, req common.Request) (*client.ReplyStream, error) {
+	ret := _m.Called(ctx, req)
+
+	var r0 *client.ReplyStream
+	if rf, ok := ret.Get(0).(func(context.Context, common.Request) *client.ReplyStream); ok {
+		r0 = rf(ctx, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*client.ReplyStream)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, common.Request) error); ok {
+		r1 = rf(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // ID provides a mock function with given fields:
 func (_m *OpSession) ID() uint64 {
 	ret := _m.Called()
@@ -68,6 +147,27 @@ func (_m *OpSession) ID() uint64 {
 	return r0
 }
 
+// NotificationDrops provides a mock function with given fields:
+func (_m *OpSession) NotificationDrops() map[string]uint64 {
+	ret := _m.Called()
+
+	var r0 map[string]uint64
+	if rf, ok := ret.Get(0).(func() map[string]uint64); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[string]uint64)
+		}
+	}
+
+	return r0
+}
+
+// RemoveNotificationSink provides a mock function with given fields: sink
+func (_m *OpSession) RemoveNotificationSink(sink *client.NotificationSink) {
+	_m.Called(sink)
+}
+
 // ServerCapabilities provides a mock function with given fields:
 func (_m *OpSession) ServerCapabilities() []string {
 	ret := _m.Called()
@@ -0,0 +1,47 @@
+package ops
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/damianoneill/net/v2/netconf/common"
+)
+
+// ErrCapabilityNotSupported is returned by a session created with CheckCapabilities if an operation requires a
+// capability that the server has not advertised.
+var ErrCapabilityNotSupported = errors.New("capability not supported by server")
+
+// SessionOption configures an operations session.
+type SessionOption func(*sImpl)
+
+// CheckCapabilities causes operations that require a capability that the server has not advertised, such as a
+// commit without the :candidate capability, to fail with ErrCapabilityNotSupported without sending a request.
+func CheckCapabilities() SessionOption {
+	return func(s *sImpl) {
+		s.checkCaps = true
+	}
+}
+
+// requireCapability returns an error if capability checks are enabled and the server supports none of the
+// capabilities.
+func (s *sImpl) requireCapability(capabilities ...string) error {
+	if !s.checkCaps || common.ParseCapabilities(s.ServerCapabilities()).HasCapability(capabilities...) {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrCapabilityNotSupported, strings.Join(capabilities, " or "))
+}
+
+// requireDatastore returns an error if capability checks are enabled and the server does not support the
+// capability required to access the named datastore, writing to it if write is true.
+func (s *sImpl) requireDatastore(name string, write bool) error {
+	switch {
+	case name == CandidateCfg:
+		return s.requireCapability(common.CapCandidate)
+	case name == StartupCfg:
+		return s.requireCapability(common.CapStartup)
+	case name == RunningCfg && write:
+		return s.requireCapability(common.CapWritableRunning)
+	}
+	return nil
+}
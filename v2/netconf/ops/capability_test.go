@@ -0,0 +1,58 @@
+package ops
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/damianoneill/net/v2/netconf/common"
+	"github.com/damianoneill/net/v2/netconf/mocks"
+	"github.com/damianoneill/net/v2/netconf/testserver"
+
+	assert "github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+)
+
+func TestCheckCapabilities(t *testing.T) {
+	ts := testserver.NewTestNetconfServer(t).WithCapabilities([]string{common.CapBase10, common.CapCandidate})
+	defer ts.Close()
+
+	sshConfig := &ssh.ClientConfig{
+		User:            testserver.TestUserName,
+		Auth:            []ssh.AuthMethod{ssh.Password(testserver.TestPassword)},
+		HostKeyCallback: ssh.InsecureIgnoreHostKey(), //nolint: gosec
+	}
+	ncs, err := NewSession(context.Background(), sshConfig, fmt.Sprintf("localhost:%d", ts.Port()), CheckCapabilities())
+	assert.NoError(t, err, "Failed to create session")
+	defer ncs.Close()
+
+	assert.NoError(t, ncs.Lock(CandidateCfg), "Not expecting lock to fail")
+	assert.NoError(t, ncs.EditConfig(CandidateCfg, Cfg(`<top/>`)), "Not expecting edit to fail")
+	assert.NoError(t, ncs.Commit(), "Not expecting commit to fail")
+	assert.Equal(t, 3, ts.SessionHandler(ncs.ID()).ReqCount(), "Expected requests to be sent")
+
+	err = ncs.EditConfig(RunningCfg, Cfg(`<top/>`))
+	assert.ErrorIs(t, err, ErrCapabilityNotSupported, "Expecting edit to fail")
+	assert.EqualError(t, err, "capability not supported by server: "+common.CapWritableRunning, "Unexpected error")
+	assert.ErrorIs(t, ncs.Commit(Confirmed()), ErrCapabilityNotSupported, "Expecting confirmed commit to fail")
+	assert.ErrorIs(t, ncs.Validate(DsName(CandidateCfg)), ErrCapabilityNotSupported, "Expecting validate to fail")
+	assert.ErrorIs(t, ncs.GetXpath("/top", nil, new(string)), ErrCapabilityNotSupported, "Expecting get to fail")
+	assert.ErrorIs(t, ncs.Lock(StartupCfg), ErrCapabilityNotSupported, "Expecting lock to fail")
+	_, err = ncs.CreateSubscription("", nil, time.Time{}, time.Time{})
+	assert.ErrorIs(t, err, ErrCapabilityNotSupported, "Expecting subscription to fail")
+	assert.Equal(t, 3, ts.SessionHandler(ncs.ID()).ReqCount(), "Not expecting requests to be sent")
+}
+
+func TestCheckConfirmedCommitCapabilities(t *testing.T) {
+	mcli := &mocks.OpSession{}
+	ncs := NewSessionFromClient(mcli, CheckCapabilities())
+	mcli.On("ServerCapabilities").Return([]string{common.CapBase10, common.CapCandidate, common.CapConfirmedCommit10})
+	mcli.On("Execute", createCommitRequest(ConfirmTimeout(60))).Return(&common.RPCReply{}, nil)
+
+	assert.NoError(t, ncs.Commit(ConfirmTimeout(60)), "Not expecting confirmed commit to fail")
+	assert.ErrorIs(t, ncs.Commit(Persist("p1")), ErrCapabilityNotSupported, "Expecting persistent commit to fail")
+	assert.ErrorIs(t, ncs.Commit(PersistID("p1")), ErrCapabilityNotSupported, "Expecting persist-id commit to fail")
+	assert.ErrorIs(t, ncs.CancelCommit(""), ErrCapabilityNotSupported, "Expecting cancel-commit to fail")
+	mcli.AssertExpectations(t)
+}
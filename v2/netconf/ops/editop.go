@@ -0,0 +1,81 @@
+package ops
+
+import (
+	"encoding/xml"
+
+	"github.com/damianoneill/net/v2/netconf/common"
+)
+
+// Defines the attributes that qualify the edit of individual elements of a configuration.
+
+const (
+	netconfPrefix = "nc"
+	yangPrefix    = "yang"
+)
+
+// Op holds the edit-config attributes of an element of a configuration model: the operation (RFC 6241 section 7.2)
+// applied to the element, and the position at which an entry of an ordered-by user list or leaf-list is inserted
+// (RFC 7950 section 7.8.6).
+// To qualify an element, add a field of type Op, tagged `xml:",any,attr"`, to the struct that defines it, for
+// example:
+//
+//	type Interface struct {
+//		XMLName xml.Name `xml:"urn:ietf:params:xml:ns:yang:ietf-interfaces interface"`
+//		Op      ops.Op   `xml:",any,attr"`
+//		Name    string   `xml:"name"`
+//	}
+//	cfg := &Interface{Name: "eth1", Op: ops.Operation(ops.DeleteOp)}
+//
+// The attributes are marshalled with the nc and yang prefixes, whose namespaces are declared on the element.
+// A nil Op adds no attributes.
+type Op []xml.Attr
+
+// Operation returns an Op that applies the operation, such as MergeOp, ReplaceOp, CreateOp, DeleteOp or RemoveOp,
+// to the element.
+func Operation(op string) Op {
+	return Op(nil).with(netconfPrefix, common.NetconfNS, "operation", op)
+}
+
+// Operation returns a copy of o that applies the operation to the element.
+func (o Op) Operation(op string) Op {
+	return o.with(netconfPrefix, common.NetconfNS, "operation", op)
+}
+
+// Insert returns a copy of o that inserts an entry of an ordered-by user list or leaf-list at the position, which is
+// one of InsertFirst, InsertLast, InsertBefore or InsertAfter. An Op that only inserts can be created with
+// Op{}.Insert(position).
+// The entry relative to which an entry is inserted before or after is identified by Key for a list, or Value for a
+// leaf-list.
+func (o Op) Insert(position string) Op {
+	return o.with(yangPrefix, YangNS, "insert", position)
+}
+
+// Key returns a copy of o that identifies the list entry relative to which the entry is inserted, by its key
+// predicates, for example "[ex:name='eth0']".
+func (o Op) Key(key string) Op {
+	return o.with(yangPrefix, YangNS, "key", key)
+}
+
+// Value returns a copy of o that identifies the leaf-list entry relative to which the entry is inserted.
+func (o Op) Value(value string) Op {
+	return o.with(yangPrefix, YangNS, "value", value)
+}
+
+// with returns a copy of o with the prefixed attribute, replacing any existing value, and the declaration of the
+// prefix.
+func (o Op) with(prefix, ns, name, value string) Op {
+	result := make(Op, 0, len(o)+2)
+	result = append(result, o...)
+	result = result.set("xmlns:"+prefix, ns)
+	return result.set(prefix+":"+name, value)
+}
+
+func (o Op) set(name, value string) Op {
+	for i := range o {
+		if o[i].Name.Space == "" && o[i].Name.Local == name {
+			o[i].Value = value
+			return o
+		}
+	}
+	return append(o, xml.Attr{Name: xml.Name{Local: name}, Value: value})
+}
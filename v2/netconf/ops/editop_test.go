@@ -0,0 +1,67 @@
+package ops
+
+import (
+	"encoding/xml"
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+)
+
+type opInterfaces struct {
+	XMLName   xml.Name      `xml:"urn:ietf:params:xml:ns:yang:ietf-interfaces interfaces"`
+	Interface []opInterface `xml:"interface"`
+}
+
+type opInterface struct {
+	Op          Op       `xml:",any,attr"`
+	Name        string   `xml:"name"`
+	Description *opLeaf  `xml:"description,omitempty"`
+	DNS         []opLeaf `xml:"dns"`
+}
+
+type opLeaf struct {
+	Op    Op     `xml:",any,attr"`
+	Value string `xml:",chardata"`
+}
+
+func TestOperation(t *testing.T) {
+	cfg := &opInterfaces{Interface: []opInterface{
+		{Name: "eth0", Op: Operation(DeleteOp)},
+		{Name: "eth1", Description: &opLeaf{Op: Operation(RemoveOp)}},
+		{Name: "eth2", Op: Operation(CreateOp).Insert(InsertAfter).Key("[name='eth1']"), DNS: []opLeaf{
+			{Value: "10.0.0.2", Op: Op{}.Insert(InsertBefore).Value("10.0.0.1")},
+			{Value: "10.0.0.3"},
+		}},
+	}}
+
+	b, err := xml.Marshal(cfg)
+	assert.NoError(t, err, "Not expecting marshal to fail")
+	assert.Equal(t, `<interfaces xmlns="urn:ietf:params:xml:ns:yang:ietf-interfaces">`+
+		`<interface xmlns:nc="urn:ietf:params:xml:ns:netconf:base:1.0" nc:operation="delete"><name>eth0</name></interface>`+
+		`<interface><name>eth1</name>`+
+		`<description xmlns:nc="urn:ietf:params:xml:ns:netconf:base:1.0" nc:operation="remove"></description></interface>`+
+		`<interface xmlns:nc="urn:ietf:params:xml:ns:netconf:base:1.0" nc:operation="create" `+
+		`xmlns:yang="urn:ietf:params:xml:ns:yang:1" yang:insert="after" yang:key="[name=&#39;eth1&#39;]">`+
+		`<name>eth2</name>`+
+		`<dns xmlns:yang="urn:ietf:params:xml:ns:yang:1" yang:insert="before" yang:value="10.0.0.1">10.0.0.2</dns>`+
+		`<dns>10.0.0.3</dns></interface></interfaces>`, string(b), "Unexpected config")
+
+	req := createEditConfigRequest(RunningCfg, Cfg(&opInterfaces{Interface: []opInterface{
+		{Name: "eth0", Op: Operation(DeleteOp)},
+	}}))
+	b, _ = xml.Marshal(req)
+	assert.Contains(t, string(b), `<config><interfaces xmlns="urn:ietf:params:xml:ns:yang:ietf-interfaces">`+
+		`<interface xmlns:nc="urn:ietf:params:xml:ns:netconf:base:1.0" nc:operation="delete">`, "Unexpected request")
+}
+
+func TestOpReplacesAttributes(t *testing.T) {
+	base := Operation(MergeOp)
+	op := base.Operation(ReplaceOp).Insert(InsertFirst)
+	assert.Equal(t, Op{
+		{Name: xml.Name{Local: "xmlns:nc"}, Value: "urn:ietf:params:xml:ns:netconf:base:1.0"},
+		{Name: xml.Name{Local: "nc:operation"}, Value: ReplaceOp},
+		{Name: xml.Name{Local: "xmlns:yang"}, Value: YangNS},
+		{Name: xml.Name{Local: "yang:insert"}, Value: InsertFirst},
+	}, op, "Unexpected attributes")
+	assert.Equal(t, MergeOp, base[1].Value, "Not expecting the original to change")
+}
@@ -0,0 +1,119 @@
+package ops
+
+import (
+	"encoding/xml"
+	"errors"
+
+	"github.com/damianoneill/net/v2/netconf/common"
+)
+
+// Defines the NETCONF base notifications described by RFC 6470.
+
+// NetconfNotificationsNS is the namespace of the NETCONF base notifications.
+const NetconfNotificationsNS = "urn:ietf:params:xml:ns:yang:ietf-netconf-notifications"
+
+// Define the names of the NETCONF base notifications.
+const (
+	ConfigChange     = "netconf-config-change"
+	CapabilityChange = "netconf-capability-change"
+	SessionStart     = "netconf-session-start"
+	SessionEnd       = "netconf-session-end"
+	ConfirmedCommit  = "netconf-confirmed-commit"
+)
+
+// ErrNotNetconfEvent is returned by DecodeNetconfEvent if the notification is not a NETCONF base notification.
+var ErrNotNetconfEvent = errors.New("notification is not a netconf base notification")
+
+// SessionParams identifies the session that caused an event.
+type SessionParams struct {
+	Username   string `xml:"username"`
+	SessionID  uint64 `xml:"session-id"`
+	SourceHost string `xml:"source-host"`
+}
+
+// ChangedBy identifies the originator of a change: either the server, or the session of a user.
+type ChangedBy struct {
+	// Server is non-nil if the change was made by the server.
+	Server *struct{} `xml:"server"`
+	SessionParams
+}
+
+// ConfigEdit describes an edit made to a datastore.
+type ConfigEdit struct {
+	// The instance-identifier of the node that was changed, which uses the prefixes declared in the event.
+	Target string `xml:"target"`
+	// The operation performed, for example merge, replace, create, delete or remove.
+	Operation string `xml:"operation"`
+}
+
+// ConfigChangeEvent is sent when the configuration of the running or startup datastore is changed.
+type ConfigChangeEvent struct {
+	XMLName   xml.Name     `xml:"urn:ietf:params:xml:ns:yang:ietf-netconf-notifications netconf-config-change"`
+	ChangedBy ChangedBy    `xml:"changed-by"`
+	Datastore string       `xml:"datastore"`
+	Edits     []ConfigEdit `xml:"edit"`
+}
+
+// CapabilityChangeEvent is sent when the capabilities of the server change.
+type CapabilityChangeEvent struct {
+	XMLName   xml.Name  `xml:"urn:ietf:params:xml:ns:yang:ietf-netconf-notifications netconf-capability-change"`
+	ChangedBy ChangedBy `xml:"changed-by"`
+	Added     []string  `xml:"added-capability"`
+	Deleted   []string  `xml:"deleted-capability"`
+	Modified  []string  `xml:"modified-capability"`
+}
+
+// SessionStartEvent is sent when a NETCONF session is started.
+type SessionStartEvent struct {
+	XMLName xml.Name `xml:"urn:ietf:params:xml:ns:yang:ietf-netconf-notifications netconf-session-start"`
+	SessionParams
+}
+
+// SessionEndEvent is sent when a NETCONF session is terminated.
+type SessionEndEvent struct {
+	XMLName xml.Name `xml:"urn:ietf:params:xml:ns:yang:ietf-netconf-notifications netconf-session-end"`
+	SessionParams
+	// The id of the session that killed the session, if it was terminated by kill-session.
+	KilledBy uint64 `xml:"killed-by"`
+	// The reason for termination, for example closed, killed, dropped, timeout or bad-hello.
+	TerminationReason string `xml:"termination-reason"`
+}
+
+// ConfirmedCommitEvent is sent when a confirmed commit is started, extended, completed, cancelled or timed out.
+type ConfirmedCommitEvent struct {
+	XMLName xml.Name `xml:"urn:ietf:params:xml:ns:yang:ietf-netconf-notifications netconf-confirmed-commit"`
+	SessionParams
+	// The event, one of start, cancel, timeout, extend or complete.
+	ConfirmEvent string `xml:"confirm-event"`
+	// The number of seconds remaining until the commit is rolled back, for the start and extend events.
+	Timeout uint32 `xml:"timeout"`
+}
+
+// DecodeNetconfEvent decodes a NETCONF base notification, returning a pointer to the event type that corresponds to
+// its name, for example *SessionStartEvent.
+// ErrNotNetconfEvent is returned if the notification is not a NETCONF base notification.
+func DecodeNetconfEvent(n *common.Notification) (interface{}, error) {
+	if n.XMLName.Space != NetconfNotificationsNS {
+		return nil, ErrNotNetconfEvent
+	}
+
+	var event interface{}
+	switch n.XMLName.Local {
+	case ConfigChange:
+		event = &ConfigChangeEvent{}
+	case CapabilityChange:
+		event = &CapabilityChangeEvent{}
+	case SessionStart:
+		event = &SessionStartEvent{}
+	case SessionEnd:
+		event = &SessionEndEvent{}
+	case ConfirmedCommit:
+		event = &ConfirmedCommitEvent{}
+	default:
+		return nil, ErrNotNetconfEvent
+	}
+	if err := n.Decode(event); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
@@ -0,0 +1,56 @@
+package ops
+
+import (
+	"encoding/xml"
+	"testing"
+
+	"github.com/damianoneill/net/v2/netconf/common"
+
+	assert "github.com/stretchr/testify/require"
+)
+
+func TestDecodeNetconfEvent(t *testing.T) {
+	event, err := DecodeNetconfEvent(netconfEvent(ConfigChange, `<changed-by><username>admin</username>`+
+		`<session-id>12</session-id><source-host>10.0.0.1</source-host></changed-by><datastore>running</datastore>`+
+		`<edit><target xmlns:if="urn:if">/if:interfaces/if:interface[if:name='eth0']</target><operation>merge</operation></edit>`))
+	assert.NoError(t, err, "Not expecting decode to fail")
+	assert.Equal(t, &ConfigChangeEvent{
+		XMLName:   xml.Name{Space: NetconfNotificationsNS, Local: ConfigChange},
+		ChangedBy: ChangedBy{SessionParams: SessionParams{Username: "admin", SessionID: 12, SourceHost: "10.0.0.1"}},
+		Datastore: "running",
+		Edits:     []ConfigEdit{{Target: "/if:interfaces/if:interface[if:name='eth0']", Operation: "merge"}},
+	}, event, "Unexpected event")
+
+	event, err = DecodeNetconfEvent(netconfEvent(CapabilityChange, `<changed-by><server/></changed-by>`+
+		`<added-capability>urn:a</added-capability><deleted-capability>urn:b</deleted-capability>`))
+	assert.NoError(t, err, "Not expecting decode to fail")
+	ccEvent := event.(*CapabilityChangeEvent)
+	assert.NotNil(t, ccEvent.ChangedBy.Server, "Expected change by server")
+	assert.Equal(t, []string{"urn:a"}, ccEvent.Added, "Unexpected added capabilities")
+	assert.Equal(t, []string{"urn:b"}, ccEvent.Deleted, "Unexpected deleted capabilities")
+
+	event, err = DecodeNetconfEvent(netconfEvent(SessionStart, `<username>admin</username><session-id>12</session-id>`))
+	assert.NoError(t, err, "Not expecting decode to fail")
+	assert.Equal(t, uint64(12), event.(*SessionStartEvent).SessionID, "Unexpected session id")
+
+	event, err = DecodeNetconfEvent(netconfEvent(SessionEnd, `<username>admin</username><session-id>12</session-id>`+
+		`<killed-by>3</killed-by><termination-reason>killed</termination-reason>`))
+	assert.NoError(t, err, "Not expecting decode to fail")
+	seEvent := event.(*SessionEndEvent)
+	assert.Equal(t, uint64(3), seEvent.KilledBy, "Unexpected killed-by")
+	assert.Equal(t, "killed", seEvent.TerminationReason, "Unexpected termination reason")
+
+	event, err = DecodeNetconfEvent(netconfEvent(ConfirmedCommit, `<username>admin</username><session-id>12</session-id>`+
+		`<confirm-event>start</confirm-event><timeout>600</timeout>`))
+	assert.NoError(t, err, "Not expecting decode to fail")
+	assert.Equal(t, uint32(600), event.(*ConfirmedCommitEvent).Timeout, "Unexpected timeout")
+
+	_, err = DecodeNetconfEvent(subscriptionNotification("urn:test", SessionStart, ``))
+	assert.ErrorIs(t, err, ErrNotNetconfEvent, "Expecting decode to fail")
+	_, err = DecodeNetconfEvent(netconfEvent("netconf-other", ``))
+	assert.ErrorIs(t, err, ErrNotNetconfEvent, "Expecting decode to fail")
+}
+
+func netconfEvent(local, body string) *common.Notification {
+	return subscriptionNotification(NetconfNotificationsNS, local, body)
+}
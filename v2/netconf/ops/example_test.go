@@ -5,7 +5,9 @@ import (
 	"context"
 	"encoding/xml"
 	"fmt"
+	"os"
 
+	"github.com/damianoneill/net/v2/netconf/common"
 	"github.com/damianoneill/net/v2/netconf/testserver"
 
 	"golang.org/x/crypto/ssh"
@@ -153,6 +155,33 @@ func ExampleOpSession_GetConfigSubtree() {
 	// cfgval2
 }
 
+func ExampleOpSession_GetConfigSubtreeTo() {
+	ts := testserver.NewTestNetconfServer(nil).WithRequestHandler(testserver.SmartRequesttHandler)
+
+	sshConfig := &ssh.ClientConfig{
+		User:            testserver.TestUserName,
+		Auth:            []ssh.AuthMethod{ssh.Password(testserver.TestPassword)},
+		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+	}
+	s, err := NewSession(context.Background(), sshConfig, fmt.Sprintf("localhost:%d", ts.Port()))
+	if err != nil {
+		fmt.Printf("Failed to start session %s\n", err)
+		return
+	}
+	defer s.Close()
+
+	err = s.GetConfigSubtreeTo("<top/>", RunningCfg, os.Stdout)
+	if err != nil {
+		fmt.Printf("Failed to execute RPC:%s\n", err)
+		return
+	}
+	fmt.Println()
+
+	s.Close()
+
+	// Output: <top xmlns="urn:ietf:params:xml:ns:netconf:base:1.0"><sub attr="cfgval1"><child1>cfgval2</child1></sub></top>
+}
+
 func ExampleOpSession_GetSchema() {
 	ts := testserver.NewTestNetconfServer(nil).WithRequestHandler(testserver.SmartRequesttHandler)
 
@@ -181,3 +210,36 @@ func ExampleOpSession_GetSchema() {
 	//
 	//// etc…
 }
+
+func ExampleTransaction() {
+	ts := testserver.NewTestNetconfServer(nil).
+		WithCapabilities([]string{common.CapBase10, common.CapCandidate, common.CapConfirmedCommit11})
+
+	sshConfig := &ssh.ClientConfig{
+		User:            testserver.TestUserName,
+		Auth:            []ssh.AuthMethod{ssh.Password(testserver.TestPassword)},
+		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+	}
+	s, err := NewSession(context.Background(), sshConfig, fmt.Sprintf("localhost:%d", ts.Port()))
+	if err != nil {
+		fmt.Printf("Failed to start session %s\n", err)
+		return
+	}
+	defer s.Close()
+
+	healthCheck := func(s OpSession) error {
+		var result string
+		return s.GetSubtree(`<interfaces xmlns="urn:ietf:params:xml:ns:yang:ietf-interfaces"/>`, &result)
+	}
+
+	err = NewTransaction(s, WithConfirmedCommit(120, healthCheck)).
+		EditConfig(Cfg(`<interfaces xmlns="urn:ietf:params:xml:ns:yang:ietf-interfaces"/>`)).
+		Run()
+	if err != nil {
+		fmt.Printf("Transaction failed:%s\n", err)
+		return
+	}
+	fmt.Println("Committed")
+
+	// Output: Committed
+}
@@ -0,0 +1,252 @@
+package ops
+
+import (
+	"encoding"
+	"encoding/xml"
+	"errors"
+	"reflect"
+	"strings"
+)
+
+// Defines builders for subtree filters, as described by RFC 6241 section 6.
+
+// ErrInvalidFilterStruct is returned by StructFilter if the value is not a struct with an XMLName field that names
+// the top-level element.
+var ErrInvalidFilterStruct = errors.New("filter requires a struct with a named XMLName field")
+
+var (
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	xmlUnmarshalerType  = reflect.TypeOf((*xml.Unmarshaler)(nil)).Elem()
+)
+
+// FilterNode is a node of a subtree filter, which can be passed as the filter of a GetSubtree or GetConfigSubtree
+// operation. A containment node, created by Container with one or more children, selects its children.
+// A selection node, created by Select or by Container with no children, selects the element and all of its
+// descendants. A content match node, created by Match, selects the siblings of elements with the matching value.
+// Attribute match expressions can be added to any node with MatchAttr.
+type FilterNode struct {
+	name      string
+	namespace string
+	value     *string
+	attrs     []xml.Attr
+	children  []*FilterNode
+}
+
+// Container returns a containment node with the specified children, or a selection node if there are none.
+func Container(name string, children ...*FilterNode) *FilterNode {
+	return &FilterNode{name: name, children: children}
+}
+
+// Select returns a selection node.
+func Select(name string) *FilterNode {
+	return &FilterNode{name: name}
+}
+
+// Match returns a content match node, which selects the siblings of the leaf that has the specified value.
+func Match(name, value string) *FilterNode {
+	return &FilterNode{name: name, value: &value}
+}
+
+// Namespace defines the namespace of the node, which is otherwise inherited from its parent.
+func (n *FilterNode) Namespace(ns string) *FilterNode {
+	n.namespace = ns
+	return n
+}
+
+// MatchAttr adds an attribute match expression to the node, which selects elements that have the attribute value.
+func (n *FilterNode) MatchAttr(name xml.Name, value string) *FilterNode {
+	n.attrs = append(n.attrs, xml.Attr{Name: name, Value: value})
+	return n
+}
+
+// String returns the XML representation of the filter.
+func (n *FilterNode) String() string {
+	b, _ := xml.Marshal(n)
+	return string(b)
+}
+
+// MarshalXML implements xml.Marshaler.
+func (n *FilterNode) MarshalXML(e *xml.Encoder, _ xml.StartElement) error {
+	return n.encode(e, "")
+}
+
+func (n *FilterNode) encode(e *xml.Encoder, parentNS string) error {
+	start, ns := n.startElement(parentNS)
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	if n.value != nil {
+		if err := e.EncodeToken(xml.CharData(*n.value)); err != nil {
+			return err
+		}
+	}
+	for _, child := range n.children {
+		if err := child.encode(e, ns); err != nil {
+			return err
+		}
+	}
+	return e.EncodeToken(start.End())
+}
+
+// startElement returns the start element of the node, and its namespace, which is inherited from the parent if
+// it is not defined.
+func (n *FilterNode) startElement(parentNS string) (start xml.StartElement, ns string) {
+	start = xml.StartElement{Name: xml.Name{Local: n.name}}
+	ns = parentNS
+	if n.namespace != "" && n.namespace != parentNS {
+		ns = n.namespace
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "xmlns"}, Value: ns})
+	}
+	start.Attr = append(start.Attr, n.attrs...)
+	return start, ns
+}
+
+// child returns the child node with the name and namespace, adding it if it does not exist.
+func (n *FilterNode) child(name, namespace string) *FilterNode {
+	for _, c := range n.children {
+		if c.name == name && c.namespace == namespace && c.value == nil {
+			return c
+		}
+	}
+	c := &FilterNode{name: name, namespace: namespace}
+	n.children = append(n.children, c)
+	return c
+}
+
+// Subtree defines a filter with several top-level nodes.
+type Subtree []*FilterNode
+
+// MarshalXML implements xml.Marshaler.
+func (s Subtree) MarshalXML(e *xml.Encoder, _ xml.StartElement) error {
+	for _, n := range s {
+		if err := n.encode(e, ""); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// StructFilter returns a subtree filter that selects the elements that are decoded into v, which must be a struct,
+// or a pointer to a struct, with an XMLName field whose tag names the top-level element.
+// The filter is derived from the xml tags of the fields of the struct: a struct field is a containment node, and
+// any other field is a selection node. A struct that is decoded with an any or innerxml field is a selection node,
+// as are fields with types that implement xml.Unmarshaler or encoding.TextUnmarshaler, such as time.Time.
+func StructFilter(v interface{}) (*FilterNode, error) {
+	t, ns, name := structXMLName(v)
+	if name == "" {
+		return nil, ErrInvalidFilterStruct
+	}
+
+	root := Container(name).Namespace(ns)
+	if !addStructFields(root, t, map[reflect.Type]bool{t: true}) {
+		root.children = nil
+	}
+	return root, nil
+}
+
+// structXMLName returns the struct type of v, which may be a pointer, and the namespace and name defined by the tag
+// of its XMLName field. The name is empty if v is not a struct with a named XMLName field.
+func structXMLName(v interface{}) (t reflect.Type, ns, name string) {
+	t = reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return t, "", ""
+	}
+	if f, ok := t.FieldByName("XMLName"); ok {
+		ns, name = splitXMLName(strings.Split(f.Tag.Get("xml"), ",")[0])
+	}
+	return t, ns, name
+}
+
+// addStructFields adds the nodes that select the fields of the struct type t to node, returning false if the
+// fields cannot be selected individually.
+func addStructFields(node *FilterNode, t reflect.Type, visiting map[reflect.Type]bool) bool {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("xml")
+		if (f.PkgPath != "" && !f.Anonymous) || tag == "-" || f.Name == "XMLName" {
+			continue
+		}
+
+		tagName, flags, _ := strings.Cut(tag, ",")
+		options := strings.Split(flags, ",")
+		if hasTagOption(options, "attr", "chardata", "cdata", "comment") {
+			continue
+		}
+		if hasTagOption(options, "innerxml", "any") {
+			return false
+		}
+
+		ft := fieldElemType(f.Type)
+		if f.Anonymous && tagName == "" {
+			if ft.Kind() == reflect.Struct && !addStructFields(node, ft, visiting) {
+				return false
+			}
+			continue
+		}
+		if tagName == "" {
+			tagName = f.Name
+		}
+
+		ns, name := splitXMLName(tagName)
+		parents := strings.Split(name, ">")
+		parent := node
+		for _, p := range parents[:len(parents)-1] {
+			parent = parent.child(p, "")
+		}
+		leaf := parent.child(parents[len(parents)-1], ns)
+
+		if isFilterLeaf(ft) || visiting[ft] {
+			continue
+		}
+		visiting[ft] = true
+		if !addStructFields(leaf, ft, visiting) {
+			leaf.children = nil
+		}
+		delete(visiting, ft)
+	}
+	return true
+}
+
+// fieldElemType returns the type of the elements decoded into a field of type t.
+func fieldElemType(t reflect.Type) reflect.Type {
+	for {
+		switch {
+		case t.Kind() == reflect.Ptr:
+			t = t.Elem()
+		case (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) && t.Elem().Kind() != reflect.Uint8:
+			t = t.Elem()
+		default:
+			return t
+		}
+	}
+}
+
+func isFilterLeaf(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct {
+		return true
+	}
+	pt := reflect.PtrTo(t)
+	return pt.Implements(textUnmarshalerType) || pt.Implements(xmlUnmarshalerType)
+}
+
+func hasTagOption(options []string, names ...string) bool {
+	for _, option := range options {
+		for _, name := range names {
+			if option == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// splitXMLName splits the name from an xml tag into its namespace and name.
+func splitXMLName(tagName string) (ns, name string) {
+	if i := strings.LastIndex(tagName, " "); i >= 0 {
+		return tagName[:i], tagName[i+1:]
+	}
+	return "", tagName
+}
@@ -0,0 +1,76 @@
+package ops
+
+import (
+	"encoding/xml"
+	"testing"
+	"time"
+
+	assert "github.com/stretchr/testify/require"
+)
+
+func TestFilterBuilder(t *testing.T) {
+	f := Container("interfaces",
+		Container("interface",
+			Match("name", "eth0"),
+			Select("mtu"),
+			Select("config").Namespace("urn:vendor"),
+			Container("statistics").MatchAttr(xml.Name{Local: "type"}, "full"),
+		),
+	).Namespace("urn:ietf:params:xml:ns:yang:ietf-interfaces")
+
+	assert.Equal(t, `<interfaces xmlns="urn:ietf:params:xml:ns:yang:ietf-interfaces"><interface>`+
+		`<name>eth0</name><mtu></mtu><config xmlns="urn:vendor"></config><statistics type="full"></statistics>`+
+		`</interface></interfaces>`, f.String(), "Unexpected filter")
+
+	b, err := xml.Marshal(createGetSubtreeRequest(Subtree{
+		Select("system").Namespace("urn:system"),
+		Match("user", "a&b").Namespace("urn:aaa"),
+	}))
+	assert.NoError(t, err, "Not expecting marshal to fail")
+	assert.Equal(t, `<get><filter type="subtree"><system xmlns="urn:system"></system>`+
+		`<user xmlns="urn:aaa">a&amp;b</user></filter></get>`, string(b), "Unexpected request")
+}
+
+type filterCommon struct {
+	Description string `xml:"description"`
+}
+
+type filterInterface struct {
+	filterCommon
+	Name       string    `xml:"name"`
+	Type       string    `xml:"type,attr"`
+	Enabled    *bool     `xml:"enabled"`
+	Changed    time.Time `xml:"last-change"`
+	Counters   []uint64  `xml:"statistics>counter"`
+	Discards   uint64    `xml:"statistics>discards"`
+	Extensions struct {
+		Any string `xml:",innerxml"`
+	} `xml:"urn:vendor extensions"`
+	Parent  *filterInterface `xml:"parent"`
+	ignored string
+	Ignored string `xml:"-"`
+}
+
+func TestStructFilter(t *testing.T) {
+	var result struct {
+		XMLName    xml.Name          `xml:"urn:ietf:params:xml:ns:yang:ietf-interfaces interfaces"`
+		Interfaces []filterInterface `xml:"interface"`
+		Count      int
+	}
+	f, err := StructFilter(&result)
+	assert.NoError(t, err, "Not expecting filter to fail")
+	assert.Equal(t, `<interfaces xmlns="urn:ietf:params:xml:ns:yang:ietf-interfaces"><interface>`+
+		`<description></description><name></name><enabled></enabled><last-change></last-change>`+
+		`<statistics><counter></counter><discards></discards></statistics>`+
+		`<extensions xmlns="urn:vendor"></extensions><parent></parent>`+
+		`</interface><Count></Count></interfaces>`, f.String(), "Unexpected filter")
+
+	f, err = StructFilter(NetconfState{})
+	assert.NoError(t, err, "Not expecting filter to fail")
+	assert.Contains(t, f.String(), `<datastores><datastore><name></name><locks><global-lock>`, "Unexpected filter")
+
+	_, err = StructFilter(&struct{ Name string }{})
+	assert.ErrorIs(t, err, ErrInvalidFilterStruct, "Expecting filter to fail")
+	_, err = StructFilter("interfaces")
+	assert.ErrorIs(t, err, ErrInvalidFilterStruct, "Expecting filter to fail")
+}
@@ -0,0 +1,103 @@
+package ops
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+)
+
+// Defines generic operations that return typed results.
+
+// ErrInvalidRPCInput is returned by Call if the input is not a struct with an XMLName field that defines the name
+// and namespace of the rpc.
+var ErrInvalidRPCInput = errors.New("rpc input requires a struct with an XMLName field that defines its name and namespace")
+
+// Get issues a get request and returns the reply data decoded into a T, which should be a struct with xml tags.
+// If filter is nil, the subtree filter derived from T by StructFilter is used; otherwise filter is defined as for
+// GetSubtree.
+func Get[T any](s OpSession, filter interface{}, options ...GetOption) (*T, error) {
+	result := new(T)
+	filter, err := resultFilter(filter, result)
+	if err != nil {
+		return nil, err
+	}
+	if err = s.GetSubtree(filter, result, options...); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetConfig issues a get-config request for the source datastore and returns the reply data decoded into a T,
+// which should be a struct with xml tags.
+// If filter is nil, the subtree filter derived from T by StructFilter is used; otherwise filter is defined as for
+// GetConfigSubtree.
+func GetConfig[T any](s OpSession, source string, filter interface{}, options ...GetOption) (*T, error) {
+	result := new(T)
+	filter, err := resultFilter(filter, result)
+	if err != nil {
+		return nil, err
+	}
+	if err = s.GetConfigSubtree(filter, source, result, options...); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Call issues the rpc defined by input, and returns the reply decoded into an Out.
+// input must be a struct, or pointer to a struct, with an XMLName field whose tag defines the namespace and name of
+// the rpc; the remaining fields define its input parameters.
+// If Out has an XMLName field that names an element, the first such element in the reply is decoded; otherwise
+// the fields of Out are decoded from the children of the rpc-reply element, which is how the output parameters
+// of a YANG rpc are returned.
+func Call[In, Out any](s OpSession, input In) (*Out, error) {
+	if _, ns, name := structXMLName(input); ns == "" || name == "" {
+		return nil, ErrInvalidRPCInput
+	}
+
+	reply, err := s.Execute(input)
+	if err != nil {
+		return nil, err
+	}
+	output := new(Out)
+	if err = decodeRPCOutput(reply.Data, output); err != nil {
+		return nil, err
+	}
+	return output, nil
+}
+
+// resultFilter returns filter, or the filter derived from result if filter is nil.
+func resultFilter(filter, result interface{}) (interface{}, error) {
+	if filter != nil {
+		return filter, nil
+	}
+	return StructFilter(result)
+}
+
+// decodeRPCOutput decodes the content of an rpc-reply into output.
+func decodeRPCOutput(data string, output interface{}) error {
+	_, ns, name := structXMLName(output)
+	if name == "" {
+		return xml.Unmarshal([]byte("<output>"+data+"</output>"), output)
+	}
+
+	d := xml.NewDecoder(strings.NewReader(data))
+	for {
+		token, err := d.Token()
+		if err == io.EOF {
+			// The element is not present.
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			if start.Name.Local == name && (ns == "" || start.Name.Space == ns) {
+				return d.DecodeElement(output, &start)
+			}
+			if err = d.Skip(); err != nil {
+				return err
+			}
+		}
+	}
+}
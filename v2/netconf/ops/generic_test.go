@@ -0,0 +1,98 @@
+package ops
+
+import (
+	"encoding/xml"
+	"errors"
+	"testing"
+
+	"github.com/damianoneill/net/v2/netconf/common"
+
+	assert "github.com/stretchr/testify/require"
+)
+
+type genericInterfaces struct {
+	XMLName   xml.Name `xml:"urn:ietf:params:xml:ns:yang:ietf-interfaces interfaces"`
+	Interface []struct {
+		Name    string `xml:"name"`
+		Enabled bool   `xml:"enabled"`
+	} `xml:"interface"`
+}
+
+const genericInterfacesReply = `<data><interfaces xmlns="urn:ietf:params:xml:ns:yang:ietf-interfaces">` +
+	`<interface><name>eth0</name><enabled>true</enabled></interface>` +
+	`<interface><name>eth1</name><enabled>false</enabled></interface></interfaces></data>`
+
+func TestGenericGet(t *testing.T) {
+	ncs, mcli := newOpsSessionWithMockClient(t)
+	filter, _ := StructFilter(&genericInterfaces{})
+	mcli.On("Execute", createGetSubtreeRequest(filter)).Return(&common.RPCReply{Data: genericInterfacesReply}, nil)
+
+	result, err := Get[genericInterfaces](ncs, nil)
+	assert.NoError(t, err, "Not expecting get to fail")
+	assert.Len(t, result.Interface, 2, "Unexpected interfaces")
+	assert.Equal(t, "eth0", result.Interface[0].Name, "Unexpected interface")
+	assert.True(t, result.Interface[0].Enabled, "Unexpected interface")
+	mcli.AssertExpectations(t)
+
+	_, err = Get[string](ncs, nil)
+	assert.ErrorIs(t, err, ErrInvalidFilterStruct, "Expecting get to fail")
+}
+
+func TestGenericGetConfig(t *testing.T) {
+	ncs, mcli := newOpsSessionWithMockClient(t)
+	filter := Container("interfaces").Namespace("urn:ietf:params:xml:ns:yang:ietf-interfaces")
+	mcli.On("Execute", createGetConfigSubtreeRequest(filter, CandidateCfg)).Return(&common.RPCReply{Data: genericInterfacesReply}, nil)
+	mcli.On("Execute", createGetConfigSubtreeRequest(filter, RunningCfg)).Return(nil, errors.New("failed"))
+
+	result, err := GetConfig[genericInterfaces](ncs, CandidateCfg, filter)
+	assert.NoError(t, err, "Not expecting get-config to fail")
+	assert.Equal(t, "eth1", result.Interface[1].Name, "Unexpected interface")
+
+	result, err = GetConfig[genericInterfaces](ncs, RunningCfg, filter)
+	assert.EqualError(t, err, "failed", "Expecting get-config to fail")
+	assert.Nil(t, result, "Not expecting result")
+}
+
+type genericPingInput struct {
+	XMLName     xml.Name `xml:"urn:vendor:ping ping"`
+	Destination string   `xml:"destination"`
+	Count       int      `xml:"count,omitempty"`
+	VRF         string   `xml:"urn:vendor:routing vrf,omitempty"`
+}
+
+type genericPingOutput struct {
+	Sent     int `xml:"sent"`
+	Received int `xml:"received"`
+}
+
+type genericPingResult struct {
+	XMLName xml.Name `xml:"urn:vendor:ping result"`
+	Loss    int      `xml:"loss"`
+}
+
+func TestGenericCall(t *testing.T) {
+	ncs, mcli := newOpsSessionWithMockClient(t)
+	input := genericPingInput{Destination: "10.0.0.1", Count: 3, VRF: "mgmt"}
+	mcli.On("Execute", input).Return(&common.RPCReply{
+		Data: `<sent xmlns="urn:vendor:ping">3</sent><received xmlns="urn:vendor:ping">2</received>`,
+	}, nil)
+	mcli.On("Execute", &input).Return(&common.RPCReply{
+		Data: `<other/><result xmlns="urn:other"><loss>0</loss></result><result xmlns="urn:vendor:ping"><loss>33</loss></result>`,
+	}, nil)
+
+	output, err := Call[genericPingInput, genericPingOutput](ncs, input)
+	assert.NoError(t, err, "Not expecting call to fail")
+	assert.Equal(t, &genericPingOutput{Sent: 3, Received: 2}, output, "Unexpected output")
+
+	result, err := Call[*genericPingInput, genericPingResult](ncs, &input)
+	assert.NoError(t, err, "Not expecting call to fail")
+	assert.Equal(t, 33, result.Loss, "Unexpected result")
+
+	_, err = Call[struct{ Name string }, genericPingOutput](ncs, struct{ Name string }{})
+	assert.ErrorIs(t, err, ErrInvalidRPCInput, "Expecting call to fail")
+	mcli.AssertExpectations(t)
+
+	b, _ := xml.Marshal(input)
+	assert.Equal(t, `<ping xmlns="urn:vendor:ping"><destination>10.0.0.1</destination><count>3</count>`+
+		`<vrf xmlns="urn:vendor:routing">mgmt</vrf></ping>`, string(b), "Unexpected rpc")
+}
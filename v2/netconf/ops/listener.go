@@ -0,0 +1,243 @@
+package ops
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/imdario/mergo"
+
+	"github.com/damianoneill/net/v2/netconf/client"
+	"github.com/damianoneill/net/v2/netconf/common"
+)
+
+// ErrNoReplayTime is reported by a Gap if the subscription was lost before any notification was received, so
+// there is no time from which notifications can be replayed.
+var ErrNoReplayTime = errors.New("no notification received from which to replay")
+
+// Gap describes a period in which notifications may have been lost, because a NotificationListener could not
+// re-establish its subscription with replay.
+type Gap struct {
+	// The event time of the last notification received before the subscription was lost, or zero if none had
+	// been received.
+	From time.Time
+	// The time, according to the local clock, at which the subscription was re-established.
+	Until time.Time
+	// The reason that the missed notifications could not be replayed.
+	Err error
+}
+
+// ListenerConfig defines properties that configure the behaviour of a NotificationListener.
+type ListenerConfig struct {
+	// The event stream; the NETCONF stream if empty.
+	Stream string
+	// The filter that selects the events delivered; all events if nil.
+	Filter NotificationFilter
+	// The time, if any, from which stored events are replayed when the subscription is first created.
+	StartTime time.Time
+	// The reconnection behaviour of the session.
+	Reconnect *client.ReconnectConfig
+	// The number of notifications that can be buffered before they are dropped by the session.
+	BufferSize int
+	// The delay before a failed subscription is retried.
+	RetryInterval time.Duration
+	// GapHandler, if defined, is called when the subscription is re-established without replay.
+	GapHandler func(gap Gap)
+}
+
+// DefaultListenerConfig defines the default notification listener configuration.
+var DefaultListenerConfig = &ListenerConfig{
+	Reconnect:     client.DefaultReconnectConfig,
+	BufferSize:    subscriptionBufferSize,
+	RetryInterval: time.Second,
+}
+
+// NotificationListener maintains an event stream subscription (RFC 5277) on a dedicated session, re-establishing the
+// session and subscription when the transport connection is lost.
+// The subscription is re-established with a start time equal to the time of the last event received, so that the
+// server replays any events that were missed; replayed events that have already been delivered are discarded.
+// If the server rejects the replay, for example because the stream does not support replay, the subscription is
+// re-established without replay and the Gap reported.
+type NotificationListener struct {
+	ctx context.Context
+	cfg *ListenerConfig
+	rs  *client.ResilientSession
+
+	events    chan *common.Notification
+	replaying bool
+
+	mu   sync.Mutex
+	last time.Time
+	// The events received with the last event time, used to discard replayed duplicates.
+	seen map[string]struct{}
+
+	closing chan struct{}
+	once    sync.Once
+	wg      sync.WaitGroup
+}
+
+// NewNotificationListener establishes a session using the dialer, and creates the subscription defined by lcfg.
+// An error is returned if the initial session or subscription cannot be established.
+func NewNotificationListener(ctx context.Context, dialer client.Dialer, cfg *client.Config, lcfg *ListenerConfig) (*NotificationListener, error) {
+	resolvedConfig := *lcfg
+	_ = mergo.Merge(&resolvedConfig, DefaultListenerConfig)
+
+	rs, err := client.NewResilientSession(ctx, dialer, cfg, resolvedConfig.Reconnect)
+	if err != nil {
+		return nil, err
+	}
+
+	l := &NotificationListener{
+		ctx:       ctx,
+		cfg:       &resolvedConfig,
+		rs:        rs,
+		events:    make(chan *common.Notification),
+		replaying: !resolvedConfig.StartTime.IsZero(),
+		seen:      make(map[string]struct{}),
+		closing:   make(chan struct{}),
+	}
+
+	nchan, err := l.subscribe(resolvedConfig.StartTime)
+	if err != nil {
+		rs.Close()
+		return nil, err
+	}
+
+	l.wg.Add(1)
+	go l.run(nchan)
+	return l, nil
+}
+
+// Notifications returns the channel that delivers the events received. The channel is closed when the listener is
+// closed, or reconnection abandoned.
+func (l *NotificationListener) Notifications() <-chan *common.Notification {
+	return l.events
+}
+
+// LastEventTime returns the event time of the last notification received.
+func (l *NotificationListener) LastEventTime() time.Time {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.last
+}
+
+// Close closes the session and stops the listener.
+func (l *NotificationListener) Close() {
+	l.once.Do(func() {
+		close(l.closing)
+		l.rs.Close()
+		l.wg.Wait()
+	})
+}
+
+// run delivers notifications, re-establishing the subscription whenever the session is lost.
+func (l *NotificationListener) run(nchan chan *common.Notification) {
+	defer l.wg.Done()
+	defer close(l.events)
+
+	for {
+		l.consume(nchan)
+		for {
+			if err := l.rs.WaitConnected(l.ctx); err != nil {
+				return
+			}
+			var err error
+			if nchan, err = l.resubscribe(); err == nil {
+				break
+			}
+			select {
+			case <-time.After(l.cfg.RetryInterval):
+			case <-l.closing:
+				return
+			}
+		}
+	}
+}
+
+// consume delivers the notifications received by the subscription, until the session is lost.
+func (l *NotificationListener) consume(nchan chan *common.Notification) {
+	for n := range nchan {
+		switch n.XMLName {
+		case ReplayCompleteEvent:
+			l.replaying = false
+			continue
+		case NotificationCompleteEvent:
+			continue
+		}
+		if !l.accept(n) {
+			continue
+		}
+		select {
+		case l.events <- n:
+		case <-l.closing:
+			return
+		}
+	}
+}
+
+// accept records the event time of the notification, returning false if it is a replayed duplicate.
+func (l *NotificationListener) accept(n *common.Notification) bool {
+	t := n.EventTime
+	if t.IsZero() {
+		return true
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.replaying {
+		if t.Before(l.last) {
+			return false
+		}
+		if _, ok := l.seen[n.Event]; ok && t.Equal(l.last) {
+			return false
+		}
+	}
+	switch {
+	case t.After(l.last):
+		l.last = t
+		l.seen = map[string]struct{}{n.Event: {}}
+	case t.Equal(l.last):
+		l.seen[n.Event] = struct{}{}
+	}
+	return true
+}
+
+// resubscribe re-establishes the subscription, with replay from the last event time if possible.
+func (l *NotificationListener) resubscribe() (chan *common.Notification, error) {
+	last := l.LastEventTime()
+	if !last.IsZero() {
+		nchan, err := l.subscribe(last)
+		if err == nil {
+			l.replaying = true
+			return nchan, nil
+		}
+		var rpcErrs *common.RPCErrors
+		if !errors.As(err, &rpcErrs) {
+			return nil, err
+		}
+		return l.subscribeWithGap(last, err)
+	}
+	return l.subscribeWithGap(last, ErrNoReplayTime)
+}
+
+func (l *NotificationListener) subscribeWithGap(last time.Time, cause error) (chan *common.Notification, error) {
+	nchan, err := l.subscribe(time.Time{})
+	if err != nil {
+		return nil, err
+	}
+	l.replaying = false
+	if l.cfg.GapHandler != nil {
+		l.cfg.GapHandler(Gap{From: last, Until: time.Now(), Err: cause})
+	}
+	return nchan, nil
+}
+
+func (l *NotificationListener) subscribe(startTime time.Time) (chan *common.Notification, error) {
+	nchan := make(chan *common.Notification, l.cfg.BufferSize)
+	req := createCreateSubscriptionRequest(l.cfg.Stream, l.cfg.Filter, startTime, time.Time{})
+	if _, err := l.rs.Subscribe(req, nchan); err != nil {
+		return nil, err
+	}
+	return nchan, nil
+}
@@ -0,0 +1,164 @@
+package ops
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/damianoneill/net/v2/netconf/client"
+	"github.com/damianoneill/net/v2/netconf/testserver"
+
+	assert "github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+)
+
+func TestNotificationListenerReplays(t *testing.T) {
+	ts := testserver.NewTestNetconfServer(t)
+	defer ts.Close()
+
+	l, err := NewNotificationListener(context.Background(), testListenerDialer(ts), client.DefaultConfig, testListenerConfig())
+	assert.NoError(t, err, "Not expecting listener to fail")
+	defer l.Close()
+
+	t1 := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	t2 := t1.Add(time.Second)
+	sh := ts.LastHandler()
+	sh.SendNotificationAt(t1, listenerEvent(1)).SendNotificationAt(t2, listenerEvent(2))
+	assert.Equal(t, listenerEvent(1), (<-l.Notifications()).Event, "Unexpected event")
+	assert.Equal(t, listenerEvent(2), (<-l.Notifications()).Event, "Unexpected event")
+	assert.Equal(t, t2, l.LastEventTime(), "Unexpected last event time")
+
+	// Drop the session; the listener should resubscribe with replay from the last event.
+	sh.Close()
+	sh = waitForResubscription(t, ts, sh)
+	assert.Equal(t, `<startTime>2020-01-02T03:04:06Z</startTime>`, sh.LastReq().Body, "Expected replay request")
+
+	// Replay the overlapping events, which should be discarded.
+	sh.SendNotificationAt(t1, listenerEvent(1)).SendNotificationAt(t2, listenerEvent(2)).
+		SendNotificationAt(t2, listenerEvent(3)).SendNotification(replayCompleteXML).
+		SendNotificationAt(t2, listenerEvent(2))
+	assert.Equal(t, listenerEvent(3), (<-l.Notifications()).Event, "Expected duplicates to be discarded")
+	assert.Equal(t, listenerEvent(2), (<-l.Notifications()).Event, "Expected live events after replay")
+}
+
+func TestNotificationListenerGap(t *testing.T) {
+	ts := testserver.NewTestNetconfServer(t)
+	defer ts.Close()
+
+	gaps := make(chan Gap, 1)
+	lcfg := testListenerConfig()
+	lcfg.GapHandler = func(gap Gap) {
+		gaps <- gap
+	}
+	l, err := NewNotificationListener(context.Background(), testListenerDialer(ts), client.DefaultConfig, lcfg)
+	assert.NoError(t, err, "Not expecting listener to fail")
+	defer l.Close()
+
+	t1 := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	sh := ts.LastHandler()
+	sh.SendNotificationAt(t1, listenerEvent(1))
+	<-l.Notifications()
+
+	// Subsequent sessions reject the replay request.
+	ts.WithRequestHandler(testserver.FailingRequestHandler)
+	sh.Close()
+	sh = waitForResubscription(t, ts, sh)
+
+	gap := <-gaps
+	assert.Equal(t, t1, gap.From, "Unexpected gap start")
+	assert.EqualError(t, gap.Err, "netconf rpc [error] 'oops'", "Unexpected gap cause")
+	assert.Equal(t, 2, sh.ReqCount(), "Expected subscription without replay")
+	assert.Equal(t, ``, sh.LastReq().Body, "Expected subscription without replay")
+
+	sh.SendNotificationAt(t1.Add(time.Second), listenerEvent(2))
+	assert.Equal(t, listenerEvent(2), (<-l.Notifications()).Event, "Expected event after gap")
+}
+
+func TestNotificationListenerClose(t *testing.T) {
+	ts := testserver.NewTestNetconfServer(t)
+	defer ts.Close()
+
+	l, err := NewNotificationListener(context.Background(), testListenerDialer(ts), client.DefaultConfig, testListenerConfig())
+	assert.NoError(t, err, "Not expecting listener to fail")
+
+	l.Close()
+	_, ok := <-l.Notifications()
+	assert.False(t, ok, "Expected notifications to be closed")
+}
+
+func TestNotificationListenerAbandonsReconnection(t *testing.T) {
+	ts := testserver.NewTestNetconfServer(t)
+	defer ts.Close()
+
+	lcfg := testListenerConfig()
+	lcfg.Reconnect.MaxAttempts = 1
+	dialer := &failingDialer{Dialer: testListenerDialer(ts)}
+	l, err := NewNotificationListener(context.Background(), dialer, client.DefaultConfig, lcfg)
+	assert.NoError(t, err, "Not expecting listener to fail")
+	defer l.Close()
+
+	// Subsequent connection attempts fail, so reconnection is abandoned.
+	atomic.StoreInt32(&dialer.fail, 1)
+	ts.LastHandler().Close()
+
+	select {
+	case _, ok := <-l.Notifications():
+		assert.False(t, ok, "Expected notifications to be closed")
+	case <-time.After(5 * time.Second):
+		assert.Fail(t, "Expected notifications to be closed")
+	}
+}
+
+func TestNotificationListenerFailure(t *testing.T) {
+	ts := testserver.NewTestNetconfServer(t).WithRequestHandler(testserver.FailingRequestHandler)
+	defer ts.Close()
+
+	l, err := NewNotificationListener(context.Background(), testListenerDialer(ts), client.DefaultConfig, testListenerConfig())
+	assert.Error(t, err, "Expecting listener to fail")
+	assert.Nil(t, l, "Not expecting listener")
+}
+
+// waitForResubscription waits for a new session, other than sh, to create a subscription.
+func waitForResubscription(t *testing.T, ts *testserver.TestNCServer, sh *testserver.SessionHandler) *testserver.SessionHandler {
+	assert.Eventually(t, func() bool {
+		h := ts.LastHandler()
+		return h != nil && h != sh && h.ReqCount() > 0 && h.LastReq().XMLName.Local == "create-subscription"
+	}, 5*time.Second, 10*time.Millisecond, "Expected subscription to be re-established")
+	return ts.LastHandler()
+}
+
+func testListenerDialer(ts *testserver.TestNCServer) client.Dialer {
+	return client.NewSSHDialer(fmt.Sprintf("localhost:%d", ts.Port()), &ssh.ClientConfig{
+		User:            testserver.TestUserName,
+		Auth:            []ssh.AuthMethod{ssh.Password(testserver.TestPassword)},
+		HostKeyCallback: ssh.InsecureIgnoreHostKey(), //nolint: gosec
+	})
+}
+
+// failingDialer fails to dial once fail is set.
+type failingDialer struct {
+	client.Dialer
+	fail int32
+}
+
+func (d *failingDialer) Dial(ctx context.Context) (io.ReadWriteCloser, error) {
+	if atomic.LoadInt32(&d.fail) != 0 {
+		return nil, errors.New("dial failed")
+	}
+	return d.Dialer.Dial(ctx)
+}
+
+func testListenerConfig() *ListenerConfig {
+	return &ListenerConfig{
+		Reconnect:     &client.ReconnectConfig{InitialBackoff: 10 * time.Millisecond, MaxBackoff: 50 * time.Millisecond},
+		RetryInterval: 10 * time.Millisecond,
+	}
+}
+
+func listenerEvent(id int) string {
+	return fmt.Sprintf(`<event xmlns="urn:test"><id>%d</id></event>`, id)
+}
@@ -0,0 +1,315 @@
+package ops
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Defines the mirroring of the YANG modules supported by a device to a local directory.
+
+const (
+	// MirrorManifestFile is the name of the file, written to the mirror directory, that holds the MirrorManifest.
+	MirrorManifestFile = "manifest.json"
+	// DefaultMirrorConcurrency defines the number of concurrent get-schema requests issued by MirrorSchemas if it is
+	// not configured.
+	DefaultMirrorConcurrency = 4
+
+	yangFormat = "yang"
+)
+
+var (
+	yangIdentifierRE = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.-]*$`)
+	yangRevisionRE   = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+)
+
+// MirrorOption qualifies a MirrorSchemas operation.
+type MirrorOption func(*mirrorOptions)
+
+type mirrorOptions struct {
+	concurrency int
+}
+
+// MirrorConcurrency defines the maximum number of get-schema requests that are issued concurrently.
+func MirrorConcurrency(n int) MirrorOption {
+	return func(opts *mirrorOptions) {
+		opts.concurrency = n
+	}
+}
+
+// MirrorManifest records the outcome of a MirrorSchemas operation.
+type MirrorManifest struct {
+	// The modules retrieved from the device.
+	Fetched []MirroredSchema `json:"fetched"`
+	// The modules that were not retrieved, because they were already present in the directory or are not available
+	// in YANG format.
+	Skipped []MirroredSchema `json:"skipped"`
+	// The modules that could not be retrieved or written.
+	Failed []MirroredSchema `json:"failed"`
+}
+
+// MirroredSchema describes the outcome of mirroring a module.
+type MirroredSchema struct {
+	Name     string `json:"name"`
+	Revision string `json:"revision,omitempty"`
+	// The name of the file that holds the module, relative to the mirror directory.
+	File string `json:"file,omitempty"`
+	// Dependency is true if the module was not advertised by the device, but is imported or included by a module
+	// that was.
+	Dependency bool `json:"dependency,omitempty"`
+	// The reason the module was skipped, or the error that caused it to fail.
+	Reason string `json:"reason,omitempty"`
+}
+
+// MirrorSchemas retrieves, in YANG format, every module advertised by the device's schema list (GetSchemas) and
+// writes each to a file named module@revision.yang in dir, which is created if necessary.
+// The import and include statements of each module are parsed, and any modules that they reference that were not
+// advertised are also retrieved. Modules that are already present in dir are not retrieved again.
+// The outcome for each module is returned, and written to MirrorManifestFile in dir; a module that cannot be
+// retrieved is reported as failed, rather than failing the operation.
+func MirrorSchemas(s OpSession, dir string, options ...MirrorOption) (*MirrorManifest, error) {
+	opts := &mirrorOptions{concurrency: DefaultMirrorConcurrency}
+	for _, opt := range options {
+		opt(opts)
+	}
+	if opts.concurrency < 1 {
+		opts.concurrency = 1
+	}
+
+	schemas, err := s.GetSchemas()
+	if err != nil {
+		return nil, err
+	}
+	if err = os.MkdirAll(dir, 0o755); err != nil { //nolint:gosec
+		return nil, err
+	}
+
+	m := &mirror{s: s, dir: dir, manifest: &MirrorManifest{
+		Fetched: []MirroredSchema{},
+		Skipped: []MirroredSchema{},
+		Failed:  []MirroredSchema{},
+	}}
+
+	// Collect the formats in which each advertised module is available.
+	formats := make(map[ModuleRef][]string)
+	var refs []ModuleRef
+	for _, schema := range schemas {
+		ref := ModuleRef{Name: schema.Identifier, Revision: schema.Version}
+		if _, ok := formats[ref]; !ok {
+			refs = append(refs, ref)
+		}
+		formats[ref] = append(formats[ref], schema.Format)
+	}
+
+	var jobs []mirrorJob
+	for _, ref := range refs {
+		m.known = append(m.known, ref)
+		if hasYangFormat(formats[ref]) {
+			jobs = append(jobs, mirrorJob{ref: ref})
+		} else {
+			m.record(&m.manifest.Skipped, MirroredSchema{Name: ref.Name, Revision: ref.Revision,
+				Reason: "not available in yang format: " + strings.Join(formats[ref], ",")})
+		}
+	}
+
+	// Retrieve the modules, then any dependencies that they introduce, until there are no more to retrieve.
+	for len(jobs) > 0 {
+		var deps []ModuleRef
+		for _, result := range m.fetchAll(jobs, opts.concurrency) {
+			if result.resolved != nil {
+				m.known = append(m.known, *result.resolved)
+			}
+			deps = append(deps, result.deps...)
+		}
+
+		jobs = nil
+		for _, dep := range deps {
+			if !m.isKnown(dep) {
+				m.known = append(m.known, dep)
+				jobs = append(jobs, mirrorJob{ref: dep, dependency: true})
+			}
+		}
+	}
+
+	m.manifest.sort()
+	return m.manifest, m.manifest.write(dir)
+}
+
+type mirror struct {
+	s   OpSession
+	dir string
+
+	// The modules that have been, or are being, retrieved; accessed only by the goroutine running MirrorSchemas.
+	known []ModuleRef
+
+	mu       sync.Mutex
+	manifest *MirrorManifest
+}
+
+type mirrorJob struct {
+	ref        ModuleRef
+	dependency bool
+}
+
+type mirrorResult struct {
+	// The module revision, if the job requested the module without one.
+	resolved *ModuleRef
+	// The modules imported or included by the module.
+	deps []ModuleRef
+}
+
+// fetchAll executes the jobs, with at most concurrency in progress at a time.
+func (m *mirror) fetchAll(jobs []mirrorJob, concurrency int) []mirrorResult {
+	results := make([]mirrorResult, len(jobs))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i := range jobs {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i] = m.fetch(jobs[i])
+		}(i)
+	}
+	wg.Wait()
+	return results
+}
+
+// fetch retrieves a module and writes it to the mirror directory, unless it is already present.
+func (m *mirror) fetch(job mirrorJob) (result mirrorResult) {
+	entry := MirroredSchema{Name: job.ref.Name, Revision: job.ref.Revision, Dependency: job.dependency}
+	if !yangIdentifierRE.MatchString(job.ref.Name) ||
+		(job.ref.Revision != "" && !yangRevisionRE.MatchString(job.ref.Revision)) {
+		entry.Reason = "invalid module name or revision"
+		m.record(&m.manifest.Failed, entry)
+		return
+	}
+
+	if job.ref.Revision != "" {
+		entry.File = schemaFileName(job.ref.Name, job.ref.Revision)
+		if text, err := os.ReadFile(filepath.Join(m.dir, entry.File)); err == nil {
+			entry.Reason = "already present"
+			m.record(&m.manifest.Skipped, entry)
+			result.deps = moduleDependencies(string(text))
+			return
+		}
+	}
+
+	text, err := m.s.GetSchema(job.ref.Name, job.ref.Revision, yangFormat)
+	if err != nil {
+		entry.File, entry.Reason = "", err.Error()
+		m.record(&m.manifest.Failed, entry)
+		return
+	}
+	if entry.Revision == "" {
+		entry.Revision = moduleRevision(text)
+		entry.File = schemaFileName(entry.Name, entry.Revision)
+		result.resolved = &ModuleRef{Name: entry.Name, Revision: entry.Revision}
+	}
+	if err = os.WriteFile(filepath.Join(m.dir, entry.File), []byte(text), 0o644); err != nil { //nolint:gosec
+		entry.Reason = err.Error()
+		m.record(&m.manifest.Failed, entry)
+		return
+	}
+	m.record(&m.manifest.Fetched, entry)
+	result.deps = moduleDependencies(text)
+	return
+}
+
+func (m *mirror) record(list *[]MirroredSchema, entry MirroredSchema) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	*list = append(*list, entry)
+}
+
+// isKnown reports whether a module that satisfies the dependency is known; a dependency without a revision is
+// satisfied by any revision.
+func (m *mirror) isKnown(dep ModuleRef) bool {
+	for _, ref := range m.known {
+		if ref.Name == dep.Name && (dep.Revision == "" || ref.Revision == dep.Revision) {
+			return true
+		}
+	}
+	return false
+}
+
+func (mm *MirrorManifest) sort() {
+	for _, list := range [][]MirroredSchema{mm.Fetched, mm.Skipped, mm.Failed} {
+		sort.Slice(list, func(i, j int) bool {
+			if list[i].Name != list[j].Name {
+				return list[i].Name < list[j].Name
+			}
+			return list[i].Revision < list[j].Revision
+		})
+	}
+}
+
+func (mm *MirrorManifest) write(dir string) error {
+	b, err := json.MarshalIndent(mm, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err = os.WriteFile(filepath.Join(dir, MirrorManifestFile), b, 0o644); err != nil { //nolint:gosec
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	return nil
+}
+
+func hasYangFormat(formats []string) bool {
+	for _, format := range formats {
+		if identityName(format) == yangFormat {
+			return true
+		}
+	}
+	return false
+}
+
+// schemaFileName returns the name of the file that holds a module revision.
+func schemaFileName(name, revision string) string {
+	if revision == "" {
+		return name + ".yang"
+	}
+	return name + "@" + revision + ".yang"
+}
+
+// moduleRevision returns the most recent revision of the module text, or an empty string if it has none or the
+// text cannot be parsed.
+func moduleRevision(text string) string {
+	module, err := parseYangModule(text)
+	if err != nil {
+		return ""
+	}
+	latest := ""
+	for _, stmt := range module.subs {
+		if stmt.keyword == "revision" && yangRevisionRE.MatchString(stmt.arg) && stmt.arg > latest {
+			latest = stmt.arg
+		}
+	}
+	return latest
+}
+
+// moduleDependencies returns the modules and submodules imported or included by the module text.
+func moduleDependencies(text string) []ModuleRef {
+	module, err := parseYangModule(text)
+	if err != nil {
+		return nil
+	}
+	var deps []ModuleRef
+	for _, stmt := range module.subs {
+		if stmt.keyword != "import" && stmt.keyword != "include" {
+			continue
+		}
+		dep := ModuleRef{Name: stmt.arg}
+		if rd := stmt.sub("revision-date"); rd != nil {
+			dep.Revision = rd.arg
+		}
+		deps = append(deps, dep)
+	}
+	return deps
+}
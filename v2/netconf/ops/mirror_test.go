@@ -0,0 +1,134 @@
+package ops
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/damianoneill/net/v2/netconf/common"
+
+	assert "github.com/stretchr/testify/require"
+)
+
+const mirrorSchemasReply = `<data><netconf-state xmlns="urn:ietf:params:xml:ns:yang:ietf-netconf-monitoring"><schemas>
+<schema><identifier>mod-a</identifier><version>2020-01-01</version><format>yang</format></schema>
+<schema><identifier>mod-a</identifier><version>2020-01-01</version><format>yin</format></schema>
+<schema><identifier>mod-c</identifier><version>2019-01-01</version><format>ncm:yin</format></schema>
+<schema><identifier>mod-d</identifier><version>2018-01-01</version><format>ncm:yang</format></schema>
+<schema><identifier>mod-e</identifier><version>2017-01-01</version><format>yang</format></schema>
+<schema><identifier>../mod-f</identifier><version>2017-01-01</version><format>yang</format></schema>
+</schemas></netconf-state></data>`
+
+const modA = `module mod-a {
+  namespace "urn:a";
+  prefix a;
+  import mod-b { prefix b; }
+  import mod-c {
+    prefix c;
+    revision-date 2019-01-01;
+  }
+  include mod-a-sub { revision-date 2020-01-01; }
+  revision 2020-01-01;
+  revision 2019-06-01;
+}`
+
+const modB = `module mod-b {
+  namespace "urn:b";
+  prefix b;
+  revision "2015-01-01" { description "first"; }
+  revision "2016-01-01" { description "second, where a < b"; }
+}`
+
+const modASub = `submodule mod-a-sub {
+  belongs-to mod-a { prefix a; }
+  revision 2020-01-01;
+}`
+
+func TestMirrorSchemas(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "mod-d@2018-01-01.yang"), []byte(`module mod-d { import mod-b { prefix b; } }`), 0o600))
+
+	ncs, mcli := newOpsSessionWithMockClient(t)
+	mcli.On("Execute", createGetShemasRequest()).Return(&common.RPCReply{Data: mirrorSchemasReply}, nil)
+	mcli.On("Execute", createGetShemaRequest("mod-a", "2020-01-01", "yang")).Return(schemaReply(modA), nil)
+	mcli.On("Execute", createGetShemaRequest("mod-e", "2017-01-01", "yang")).Return(nil, errors.New("failed"))
+	mcli.On("Execute", createGetShemaRequest("mod-b", "", "yang")).Return(schemaReply(modB), nil).Once()
+	mcli.On("Execute", createGetShemaRequest("mod-a-sub", "2020-01-01", "yang")).Return(schemaReply(modASub), nil)
+
+	manifest, err := MirrorSchemas(ncs, dir, MirrorConcurrency(2))
+	assert.NoError(t, err, "Not expecting mirror to fail")
+	mcli.AssertExpectations(t)
+
+	assert.Equal(t, []MirroredSchema{
+		{Name: "mod-a", Revision: "2020-01-01", File: "mod-a@2020-01-01.yang"},
+		{Name: "mod-a-sub", Revision: "2020-01-01", File: "mod-a-sub@2020-01-01.yang", Dependency: true},
+		{Name: "mod-b", Revision: "2016-01-01", File: "mod-b@2016-01-01.yang", Dependency: true},
+	}, manifest.Fetched, "Unexpected fetched modules")
+	assert.Equal(t, []MirroredSchema{
+		{Name: "mod-c", Revision: "2019-01-01", Reason: "not available in yang format: ncm:yin"},
+		{Name: "mod-d", Revision: "2018-01-01", File: "mod-d@2018-01-01.yang", Reason: "already present"},
+	}, manifest.Skipped, "Unexpected skipped modules")
+	assert.Equal(t, []MirroredSchema{
+		{Name: "../mod-f", Revision: "2017-01-01", Reason: "invalid module name or revision"},
+		{Name: "mod-e", Revision: "2017-01-01", Reason: "failed"},
+	}, manifest.Failed, "Unexpected failed modules")
+
+	text, err := os.ReadFile(filepath.Join(dir, "mod-b@2016-01-01.yang"))
+	assert.NoError(t, err, "Expected module file")
+	assert.Equal(t, modB, string(text), "Unexpected module text")
+
+	b, err := os.ReadFile(filepath.Join(dir, MirrorManifestFile))
+	assert.NoError(t, err, "Expected manifest file")
+	written := &MirrorManifest{}
+	assert.NoError(t, json.Unmarshal(b, written), "Expected valid manifest")
+	assert.Equal(t, manifest, written, "Unexpected manifest")
+}
+
+func TestMirrorSchemasFailure(t *testing.T) {
+	ncs, mcli := newOpsSessionWithMockClient(t)
+	mcli.On("Execute", createGetShemasRequest()).Return(nil, errors.New("failed"))
+
+	manifest, err := MirrorSchemas(ncs, t.TempDir())
+	assert.EqualError(t, err, "failed", "Expecting mirror to fail")
+	assert.Nil(t, manifest, "Not expecting manifest")
+}
+
+func schemaReply(text string) *common.RPCReply {
+	sb := &strings.Builder{}
+	_ = xml.EscapeText(sb, []byte(text))
+	return &common.RPCReply{Data: "<data>" + sb.String() + "</data>"}
+}
+
+func TestModuleDependencies(t *testing.T) {
+	text := `module m { yang-version 1.1; namespace "urn:m"; prefix m; import a { prefix a; }
+  /* import commented { prefix c; }
+     revision 2099-01-01; */
+  // import line-commented;
+  import "b" {
+    prefix b;
+    description "a } brace, and an import x; statement";
+    revision-date '2020-02-02';
+  }
+  include sub-m { revision-date "2021-03-" + "04"; }
+  description
+    "Imports are described here:
+     import foo;
+     revision 2098-01-01;";
+  revision 2019-01-01 { description "first"; } revision "2021-05-06" {
+    description 'latest } release';
+  }
+  container c { description "import nested { prefix n; }"; }
+}`
+	assert.Equal(t, []ModuleRef{{Name: "a"}, {Name: "b", Revision: "2020-02-02"}, {Name: "sub-m", Revision: "2021-03-04"}},
+		moduleDependencies(text), "Unexpected dependencies")
+	assert.Equal(t, "2021-05-06", moduleRevision(text), "Unexpected revision")
+
+	assert.Equal(t, []ModuleRef{{Name: "m"}}, moduleDependencies(`submodule s { belongs-to m { prefix m; } include m; }`),
+		"Unexpected dependencies")
+	assert.Empty(t, moduleDependencies(`module m { import a { prefix a; }`), "Not expecting dependencies of invalid module")
+	assert.Empty(t, moduleRevision(`module m { description "unterminated; }`), "Not expecting revision of invalid module")
+}
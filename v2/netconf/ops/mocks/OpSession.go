@@ -3,6 +3,13 @@
 package mocks
 
 import (
+	context "context"
+	xml "encoding/xml"
+	io "io"
+
+	time "time"
+
+	client "github.com/damianoneill/net/v2/netconf/client"
 	common "github.com/damianoneill/net/v2/netconf/common"
 	mock "github.com/stretchr/testify/mock"
 
@@ -14,6 +21,36 @@ type OpSession struct {
 	mock.Mock
 }
 
+// AddNotificationSink provides a mock function with given fields: cfg
+func (_m *OpSession) AddNotificationSink(cfg *client.SinkConfig) *client.NotificationSink {
+	ret := _m.Called(cfg)
+
+	var r0 *client.NotificationSink
+	if rf, ok := ret.Get(0).(func(*client.SinkConfig) *client.NotificationSink); ok {
+		r0 = rf(cfg)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*client.NotificationSink)
+		}
+	}
+
+	return r0
+}
+
+// CancelCommit provides a mock function with given fields: persistID
+func (_m *OpSession) CancelCommit(persistID string) error {
+	ret := _m.Called(persistID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(persistID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Close provides a mock function with given fields:
 func (_m *OpSession) Close() {
 	_m.Called()
@@ -33,6 +70,26 @@ func (_m *OpSession) CloseSession() error {
 	return r0
 }
 
+// Commit provides a mock function with given fields: options
+func (_m *OpSession) Commit(options ...ops.CommitOption) error {
+	_va := make([]interface{}, len(options))
+	for _i := range options {
+		_va[_i] = options[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(...ops.CommitOption) error); ok {
+		r0 = rf(options...)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // CopyConfig provides a mock function with given fields: source, target
 func (_m *OpSession) CopyConfig(source ops.CfgDsOpt, target ops.CfgDsOpt) error {
 	ret := _m.Called(source, target)
@@ -47,6 +104,29 @@ func (_m *OpSession) CopyConfig(source ops.CfgDsOpt, target ops.CfgDsOpt) error
 	return r0
 }
 
+// CreateSubscription provides a mock function with given fields: stream, filter, startTime, stopTime
+func (_m *OpSession) CreateSubscription(stream string, filter ops.NotificationFilter, startTime time.Time, stopTime time.Time) (*ops.Subscription, error) {
+	ret := _m.Called(stream, filter, startTime, stopTime)
+
+	var r0 *ops.Subscription
+	if rf, ok := ret.Get(0).(func(string, ops.NotificationFilter, time.Time, time.Time) *ops.Subscription); ok {
+		r0 = rf(stream, filter, startTime, stopTime)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ops.Subscription)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, ops.NotificationFilter, time.Time, time.Time) error); ok {
+		r1 = rf(stream, filter, startTime, stopTime)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // DeleteConfig provides a mock function with given fields: target
 func (_m *OpSession) DeleteConfig(target ops.CfgDsOpt) error {
 	ret := _m.Called(target)
@@ -61,6 +141,20 @@ func (_m *OpSession) DeleteConfig(target ops.CfgDsOpt) error {
 	return r0
 }
 
+// DeleteSubscription provides a mock function with given fields: id
+func (_m *OpSession) DeleteSubscription(id uint32) error {
+	ret := _m.Called(id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uint32) error); ok {
+		r0 = rf(id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Discard provides a mock function with given fields:
 func (_m *OpSession) Discard() error {
 	ret := _m.Called()
@@ -117,6 +211,56 @@ func (_m *OpSession) EditConfigCfg(target string, config interface{}, options ..
 	return r0
 }
 
+// EditData provides a mock function with given fields: datastore, config, options
+func (_m *OpSession) EditData(datastore string, config ops.ConfigOption, options ...ops.EditOption) error {
+	_va := make([]interface{}, len(options))
+	for _i := range options {
+		_va[_i] = options[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, datastore, config)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, ops.ConfigOption, ...ops.EditOption) error); ok {
+		r0 = rf(datastore, config, options...)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// EstablishSubscription provides a mock function with given fields: options
+func (_m *OpSession) EstablishSubscription(options ...ops.SubscriptionOption) (*ops.DynamicSubscription, error) {
+	_va := make([]interface{}, len(options))
+	for _i := range options {
+		_va[_i] = options[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	var r0 *ops.DynamicSubscription
+	if rf, ok := ret.Get(0).(func(...ops.SubscriptionOption) *ops.DynamicSubscription); ok {
+		r0 = rf(options...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ops.DynamicSubscription)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(...ops.SubscriptionOption) error); ok {
+		r1 = rf(options...)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Execute provides a mock function with given fields: req
 func (_m *OpSession) Execute(req common.Request) (*common.RPCReply, error) {
 	ret := _m.Called(req)
@@ -154,13 +298,129 @@ func (_m *OpSession) ExecuteAsync(req common.Request, rchan chan *common.RPCRepl
 	return r0
 }
 
-// GetConfigSubtree provides a mock function with given fields: filter, source, result
-func (_m *OpSession) GetConfigSubtree(filter interface{}, source string, result interface{}) error {
-	ret := _m.Called(filter, source, result)
+// ExecuteAsyncContext provides a mock function with given fields: ctx, req, rchan
+func (_m *OpSession) ExecuteAsyncContext(ctx context.Context, req common.Request, rchan chan *common.RPCReply) error {
+	ret := _m.Called(ctx, req, rchan)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, common.Request, chan *common.RPCReply) error); ok {
+		r0 = rf(ctx, req, rchan)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ExecuteContext provides a mock function with given fields: ctx, req
+func (_m *OpSession) ExecuteContext(ctx context.Context, req common.Request) (*common.RPCReply, error) {
+	ret := _m.Called(ctx, req)
+
+	var r0 *common.RPCReply
+	if rf, ok := ret.Get(0).(func(context.Context, common.Request) *common.RPCReply); ok {
+		r0 = rf(ctx, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*common.RPCReply)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, common.Request) error); ok {
+		r1 = rf(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ExecuteStream provides a mock function with given fields: ctx, req
+func (_m *OpSession) ExecuteStream(ctx context.Context, req common.Request) (*client.ReplyStream, error) {
+	ret := _m.Called(ctx, req)
+
+	var r0 *client.ReplyStream
+	if rf, ok := ret.Get(0).(func(context.Context, common.Request) *client.ReplyStream); ok {
+		r0 = rf(ctx, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*client.ReplyStream)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, common.Request) error); ok {
+		r1 = rf(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetConfigSubtree provides a mock function with given fields: filter, source, result, options
+func (_m *OpSession) GetConfigSubtree(filter interface{}, source string, result interface{}, options ...ops.GetOption) error {
+	_va := make([]interface{}, len(options))
+	for _i := range options {
+		_va[_i] = options[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, filter, source, result)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(interface{}, string, interface{}, ...ops.GetOption) error); ok {
+		r0 = rf(filter, source, result, options...)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetConfigSubtreeFunc provides a mock function with given fields: filter, source, fn
+func (_m *OpSession) GetConfigSubtreeFunc(filter interface{}, source string, fn func(xml.Token) error) error {
+	ret := _m.Called(filter, source, fn)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(interface{}, string, func(xml.Token) error) error); ok {
+		r0 = rf(filter, source, fn)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetConfigSubtreeTo provides a mock function with given fields: filter, source, w
+func (_m *OpSession) GetConfigSubtreeTo(filter interface{}, source string, w io.Writer) error {
+	ret := _m.Called(filter, source, w)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(interface{}, string, io.Writer) error); ok {
+		r0 = rf(filter, source, w)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetConfigXpath provides a mock function with given fields: xpath, nslist, source, result, options
+func (_m *OpSession) GetConfigXpath(xpath string, nslist []ops.Namespace, source string, result interface{}, options ...ops.GetOption) error {
+	_va := make([]interface{}, len(options))
+	for _i := range options {
+		_va[_i] = options[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, xpath, nslist, source, result)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(interface{}, string, interface{}) error); ok {
-		r0 = rf(filter, source, result)
+	if rf, ok := ret.Get(0).(func(string, []ops.Namespace, string, interface{}, ...ops.GetOption) error); ok {
+		r0 = rf(xpath, nslist, source, result, options...)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -168,13 +428,20 @@ func (_m *OpSession) GetConfigSubtree(filter interface{}, source string, result
 	return r0
 }
 
-// GetConfigXpath provides a mock function with given fields: xpath, nslist, source, result
-func (_m *OpSession) GetConfigXpath(xpath string, nslist []ops.Namespace, source string, result interface{}) error {
-	ret := _m.Called(xpath, nslist, source, result)
+// GetData provides a mock function with given fields: datastore, result, options
+func (_m *OpSession) GetData(datastore string, result interface{}, options ...ops.GetDataOption) error {
+	_va := make([]interface{}, len(options))
+	for _i := range options {
+		_va[_i] = options[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, datastore, result)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(string, []ops.Namespace, string, interface{}) error); ok {
-		r0 = rf(xpath, nslist, source, result)
+	if rf, ok := ret.Get(0).(func(string, interface{}, ...ops.GetDataOption) error); ok {
+		r0 = rf(datastore, result, options...)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -182,6 +449,29 @@ func (_m *OpSession) GetConfigXpath(xpath string, nslist []ops.Namespace, source
 	return r0
 }
 
+// GetNetconfState provides a mock function with given fields:
+func (_m *OpSession) GetNetconfState() (*ops.NetconfState, error) {
+	ret := _m.Called()
+
+	var r0 *ops.NetconfState
+	if rf, ok := ret.Get(0).(func() *ops.NetconfState); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ops.NetconfState)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetSchema provides a mock function with given fields: id, version, fmt
 func (_m *OpSession) GetSchema(id string, version string, fmt string) (string, error) {
 	ret := _m.Called(id, version, fmt)
@@ -226,13 +516,43 @@ func (_m *OpSession) GetSchemas() ([]ops.Schema, error) {
 	return r0, r1
 }
 
-// GetSubtree provides a mock function with given fields: filter, result
-func (_m *OpSession) GetSubtree(filter interface{}, result interface{}) error {
-	ret := _m.Called(filter, result)
+// GetStreams provides a mock function with given fields:
+func (_m *OpSession) GetStreams() ([]ops.Stream, error) {
+	ret := _m.Called()
+
+	var r0 []ops.Stream
+	if rf, ok := ret.Get(0).(func() []ops.Stream); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]ops.Stream)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetSubtree provides a mock function with given fields: filter, result, options
+func (_m *OpSession) GetSubtree(filter interface{}, result interface{}, options ...ops.GetOption) error {
+	_va := make([]interface{}, len(options))
+	for _i := range options {
+		_va[_i] = options[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, filter, result)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(interface{}, interface{}) error); ok {
-		r0 = rf(filter, result)
+	if rf, ok := ret.Get(0).(func(interface{}, interface{}, ...ops.GetOption) error); ok {
+		r0 = rf(filter, result, options...)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -240,13 +560,20 @@ func (_m *OpSession) GetSubtree(filter interface{}, result interface{}) error {
 	return r0
 }
 
-// GetXpath provides a mock function with given fields: xpath, nslist, result
-func (_m *OpSession) GetXpath(xpath string, nslist []ops.Namespace, result interface{}) error {
-	ret := _m.Called(xpath, nslist, result)
+// GetXpath provides a mock function with given fields: xpath, nslist, result, options
+func (_m *OpSession) GetXpath(xpath string, nslist []ops.Namespace, result interface{}, options ...ops.GetOption) error {
+	_va := make([]interface{}, len(options))
+	for _i := range options {
+		_va[_i] = options[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, xpath, nslist, result)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(string, []ops.Namespace, interface{}) error); ok {
-		r0 = rf(xpath, nslist, result)
+	if rf, ok := ret.Get(0).(func(string, []ops.Namespace, interface{}, ...ops.GetOption) error); ok {
+		r0 = rf(xpath, nslist, result, options...)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -254,6 +581,29 @@ func (_m *OpSession) GetXpath(xpath string, nslist []ops.Namespace, result inter
 	return r0
 }
 
+// GetYangLibrary provides a mock function with given fields:
+func (_m *OpSession) GetYangLibrary() (*ops.YangLibrary, error) {
+	ret := _m.Called()
+
+	var r0 *ops.YangLibrary
+	if rf, ok := ret.Get(0).(func() *ops.YangLibrary); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ops.YangLibrary)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // ID provides a mock function with given fields:
 func (_m *OpSession) ID() uint64 {
 	ret := _m.Called()
@@ -268,6 +618,34 @@ func (_m *OpSession) ID() uint64 {
 	return r0
 }
 
+// InvokeAction provides a mock function with given fields: path, input, output
+func (_m *OpSession) InvokeAction(path *ops.FilterNode, input interface{}, output interface{}) error {
+	ret := _m.Called(path, input, output)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*ops.FilterNode, interface{}, interface{}) error); ok {
+		r0 = rf(path, input, output)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// InvokeRPC provides a mock function with given fields: name, namespace, input, output
+func (_m *OpSession) InvokeRPC(name string, namespace string, input interface{}, output interface{}) error {
+	ret := _m.Called(name, namespace, input, output)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string, interface{}, interface{}) error); ok {
+		r0 = rf(name, namespace, input, output)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // KillSession provides a mock function with given fields: id
 func (_m *OpSession) KillSession(id uint64) error {
 	ret := _m.Called(id)
@@ -282,6 +660,20 @@ func (_m *OpSession) KillSession(id uint64) error {
 	return r0
 }
 
+// KillSubscription provides a mock function with given fields: id
+func (_m *OpSession) KillSubscription(id uint32) error {
+	ret := _m.Called(id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uint32) error); ok {
+		r0 = rf(id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Lock provides a mock function with given fields: target
 func (_m *OpSession) Lock(target string) error {
 	ret := _m.Called(target)
@@ -296,6 +688,85 @@ func (_m *OpSession) Lock(target string) error {
 	return r0
 }
 
+// ModifySubscription provides a mock function with given fields: id, options
+func (_m *OpSession) ModifySubscription(id uint32, options ...ops.SubscriptionOption) error {
+	_va := make([]interface{}, len(options))
+	for _i := range options {
+		_va[_i] = options[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, id)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uint32, ...ops.SubscriptionOption) error); ok {
+		r0 = rf(id, options...)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NotificationDrops provides a mock function with given fields:
+func (_m *OpSession) NotificationDrops() map[string]uint64 {
+	ret := _m.Called()
+
+	var r0 map[string]uint64
+	if rf, ok := ret.Get(0).(func() map[string]uint64); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[string]uint64)
+		}
+	}
+
+	return r0
+}
+
+// PartialLock provides a mock function with given fields: selects, nslist
+func (_m *OpSession) PartialLock(selects []string, nslist []ops.Namespace) (*ops.PartialLockResult, error) {
+	ret := _m.Called(selects, nslist)
+
+	var r0 *ops.PartialLockResult
+	if rf, ok := ret.Get(0).(func([]string, []ops.Namespace) *ops.PartialLockResult); ok {
+		r0 = rf(selects, nslist)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ops.PartialLockResult)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func([]string, []ops.Namespace) error); ok {
+		r1 = rf(selects, nslist)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PartialUnlock provides a mock function with given fields: lockID
+func (_m *OpSession) PartialUnlock(lockID uint32) error {
+	ret := _m.Called(lockID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uint32) error); ok {
+		r0 = rf(lockID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RemoveNotificationSink provides a mock function with given fields: sink
+func (_m *OpSession) RemoveNotificationSink(sink *client.NotificationSink) {
+	_m.Called(sink)
+}
+
 // ServerCapabilities provides a mock function with given fields:
 func (_m *OpSession) ServerCapabilities() []string {
 	ret := _m.Called()
@@ -348,3 +819,17 @@ func (_m *OpSession) Unlock(target string) error {
 
 	return r0
 }
+
+// Validate provides a mock function with given fields: source
+func (_m *OpSession) Validate(source ops.CfgDsOpt) error {
+	ret := _m.Called(source)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(ops.CfgDsOpt) error); ok {
+		r0 = rf(source)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
@@ -19,6 +19,15 @@ const (
 	MergeOp   = "merge"
 	ReplaceOp = "replace"
 	NoneOp    = "none"
+	CreateOp  = "create"
+	DeleteOp  = "delete"
+	RemoveOp  = "remove"
+
+	// Insert positions of entries of ordered-by user lists and leaf-lists
+	InsertFirst  = "first"
+	InsertLast   = "last"
+	InsertBefore = "before"
+	InsertAfter  = "after"
 
 	// Edit Config Test Options
 	TestThenSetOpt = "test-then-set"
@@ -39,41 +48,3 @@ type Schema struct {
 	Namespace  string `xml:"namespace"`
 	Location   string `xml:"location"`
 }
-
-type NetconfState struct {
-	XMLName xml.Name `xml:"urn:ietf:params:xml:ns:yang:ietf-netconf-monitoring netconf-state"`
-	Xmlns   string   `xml:"xmlns,attr"`
-	Schemas struct {
-		Schema []Schema `xml:"schema"`
-	} `xml:"schemas"`
-	Capabilities struct {
-		Text       string   `xml:",chardata"`
-		Capability []string `xml:"capability"`
-	} `xml:"capabilities"`
-	Statistics struct {
-		Text             string `xml:",chardata"`
-		NetconfStartTime string `xml:"netconf-start-time"`
-		InBadHellos      string `xml:"in-bad-hellos"`
-		InSessions       string `xml:"in-sessions"`
-		DroppedSessions  string `xml:"dropped-sessions"`
-		InRpcs           string `xml:"in-rpcs"`
-		InBadRpcs        string `xml:"in-bad-rpcs"`
-		OutRPCErrors     string `xml:"out-rpc-errors"`
-		OutNotifications string `xml:"out-notifications"`
-	} `xml:"statistics"`
-	Sessions struct {
-		Text    string `xml:",chardata"`
-		Session struct {
-			Text             string `xml:",chardata"`
-			SessionID        string `xml:"session-id"`
-			Transport        string `xml:"transport"`
-			Username         string `xml:"username"`
-			SourceHost       string `xml:"source-host"`
-			LoginTime        string `xml:"login-time"`
-			InRpcs           string `xml:"in-rpcs"`
-			InBadRpcs        string `xml:"in-bad-rpcs"`
-			OutRPCErrors     string `xml:"out-rpc-errors"`
-			OutNotifications string `xml:"out-notifications"`
-		} `xml:"session"`
-	} `xml:"sessions"`
-}
@@ -0,0 +1,133 @@
+package ops
+
+import (
+	"encoding/xml"
+	"time"
+)
+
+// Defines the NETCONF monitoring model described by RFC 6022.
+
+// MonitoringNS is the namespace of the ietf-netconf-monitoring module.
+const MonitoringNS = "urn:ietf:params:xml:ns:yang:ietf-netconf-monitoring"
+
+// NetconfState describes the state of a NETCONF server.
+type NetconfState struct {
+	XMLName      xml.Name `xml:"urn:ietf:params:xml:ns:yang:ietf-netconf-monitoring netconf-state"`
+	Xmlns        string   `xml:"xmlns,attr"`
+	Capabilities struct {
+		Capability []string `xml:"capability"`
+	} `xml:"capabilities"`
+	Datastores struct {
+		Datastore []Datastore `xml:"datastore"`
+	} `xml:"datastores"`
+	Schemas struct {
+		Schema []Schema `xml:"schema"`
+	} `xml:"schemas"`
+	Sessions struct {
+		Session []NetconfSession `xml:"session"`
+	} `xml:"sessions"`
+	Statistics Statistics `xml:"statistics"`
+}
+
+// Datastore describes a configuration datastore, and the locks held on it.
+type Datastore struct {
+	Name string `xml:"name"`
+	// The locks held on the datastore, or nil if it is not locked.
+	Locks *DatastoreLocks `xml:"locks"`
+}
+
+// DatastoreLocks describes the locks held on a datastore: either a global lock, or one or more partial locks.
+type DatastoreLocks struct {
+	GlobalLock   *GlobalLock   `xml:"global-lock"`
+	PartialLocks []PartialLock `xml:"partial-lock"`
+}
+
+// GlobalLock describes a lock on an entire datastore.
+type GlobalLock struct {
+	LockedBySession uint64    `xml:"locked-by-session"`
+	LockedTime      time.Time `xml:"locked-time"`
+}
+
+// PartialLock describes a lock on part of a datastore (RFC 5717).
+type PartialLock struct {
+	LockID          uint32    `xml:"lock-id"`
+	LockedBySession uint64    `xml:"locked-by-session"`
+	LockedTime      time.Time `xml:"locked-time"`
+	// The xpath expressions that define the lock.
+	Select []string `xml:"select"`
+	// The instance-identifiers of the nodes that are locked.
+	LockedNodes []string `xml:"locked-node"`
+}
+
+// NetconfSession describes a session established with the server.
+type NetconfSession struct {
+	SessionID        uint64    `xml:"session-id"`
+	Transport        string    `xml:"transport"`
+	Username         string    `xml:"username"`
+	SourceHost       string    `xml:"source-host"`
+	LoginTime        time.Time `xml:"login-time"`
+	InRpcs           uint32    `xml:"in-rpcs"`
+	InBadRpcs        uint32    `xml:"in-bad-rpcs"`
+	OutRPCErrors     uint32    `xml:"out-rpc-errors"`
+	OutNotifications uint32    `xml:"out-notifications"`
+}
+
+// Statistics describes the counters maintained by the server.
+type Statistics struct {
+	NetconfStartTime time.Time `xml:"netconf-start-time"`
+	InBadHellos      uint32    `xml:"in-bad-hellos"`
+	InSessions       uint32    `xml:"in-sessions"`
+	DroppedSessions  uint32    `xml:"dropped-sessions"`
+	InRpcs           uint32    `xml:"in-rpcs"`
+	InBadRpcs        uint32    `xml:"in-bad-rpcs"`
+	OutRPCErrors     uint32    `xml:"out-rpc-errors"`
+	OutNotifications uint32    `xml:"out-notifications"`
+}
+
+// Datastore returns the named datastore, or nil if it is not present.
+func (ns *NetconfState) Datastore(name string) *Datastore {
+	for i := range ns.Datastores.Datastore {
+		if ns.Datastores.Datastore[i].Name == name {
+			return &ns.Datastores.Datastore[i]
+		}
+	}
+	return nil
+}
+
+func (s *sImpl) GetNetconfState() (*NetconfState, error) {
+	ns := &NetconfState{}
+	if err := s.handleGetRequest(createGetNetconfStateRequest(), ns); err != nil {
+		return nil, err
+	}
+	return ns, nil
+}
+
+// KillStaleSessions kills the sessions established by username that logged in before loggedInBefore, or all of the
+// user's sessions if loggedInBefore is zero. The session s itself is never killed.
+// The ids of the sessions killed are returned; if a kill-session request fails, the error is returned with the ids
+// of the sessions killed before the failure.
+func KillStaleSessions(s OpSession, username string, loggedInBefore time.Time) ([]uint64, error) {
+	ns, err := s.GetNetconfState()
+	if err != nil {
+		return nil, err
+	}
+
+	var killed []uint64
+	for _, session := range ns.Sessions.Session {
+		if session.Username != username || session.SessionID == s.ID() {
+			continue
+		}
+		if !loggedInBefore.IsZero() && !session.LoginTime.Before(loggedInBefore) {
+			continue
+		}
+		if err = s.KillSession(session.SessionID); err != nil {
+			return killed, err
+		}
+		killed = append(killed, session.SessionID)
+	}
+	return killed, nil
+}
+
+func createGetNetconfStateRequest() *GetReq {
+	return createGetSubtreeRequest(`<netconf-state xmlns="urn:ietf:params:xml:ns:yang:ietf-netconf-monitoring"/>`)
+}
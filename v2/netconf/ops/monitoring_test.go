@@ -0,0 +1,125 @@
+package ops
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/damianoneill/net/v2/netconf/common"
+
+	assert "github.com/stretchr/testify/require"
+)
+
+const netconfStateReply = `<data><netconf-state xmlns="urn:ietf:params:xml:ns:yang:ietf-netconf-monitoring">
+<capabilities><capability>urn:ietf:params:netconf:base:1.1</capability>
+<capability>urn:ietf:params:netconf:capability:candidate:1.0</capability></capabilities>
+<datastores>
+<datastore><name>running</name><locks><partial-lock><lock-id>1</lock-id><locked-by-session>4</locked-by-session>
+<locked-time>2020-01-02T03:04:05Z</locked-time><select>/if:interfaces</select>
+<locked-node>/if:interfaces/if:interface[if:name='eth0']</locked-node></partial-lock></locks></datastore>
+<datastore><name>candidate</name><locks><global-lock><locked-by-session>3</locked-by-session>
+<locked-time>2020-01-02T03:04:06+01:00</locked-time></global-lock></locks></datastore>
+<datastore><name>startup</name></datastore>
+</datastores>
+<schemas><schema><identifier>ietf-interfaces</identifier><version>2018-02-20</version><format>yang</format>
+<namespace>urn:ietf:params:xml:ns:yang:ietf-interfaces</namespace><location>NETCONF</location></schema></schemas>
+<sessions>
+<session><session-id>3</session-id><transport>netconf-ssh</transport><username>admin</username>
+<source-host>10.0.0.1</source-host><login-time>2020-01-02T03:00:00Z</login-time>
+<in-rpcs>10</in-rpcs><in-bad-rpcs>1</in-bad-rpcs><out-rpc-errors>2</out-rpc-errors><out-notifications>3</out-notifications></session>
+<session><session-id>4</session-id><transport>netconf-ssh</transport><username>oper</username>
+<login-time>2020-01-02T04:00:00Z</login-time></session>
+</sessions>
+<statistics><netconf-start-time>2020-01-01T00:00:00Z</netconf-start-time><in-bad-hellos>1</in-bad-hellos>
+<in-sessions>20</in-sessions><dropped-sessions>2</dropped-sessions><in-rpcs>300</in-rpcs><in-bad-rpcs>4</in-bad-rpcs>
+<out-rpc-errors>5</out-rpc-errors><out-notifications>60</out-notifications></statistics>
+</netconf-state></data>`
+
+func TestGetNetconfState(t *testing.T) {
+	ncs, mcli := newOpsSessionWithMockClient(t)
+	mcli.On("Execute", createGetNetconfStateRequest()).Return(&common.RPCReply{Data: netconfStateReply}, nil)
+
+	ns, err := ncs.GetNetconfState()
+	assert.NoError(t, err, "Not expecting call to fail")
+	assert.Len(t, ns.Capabilities.Capability, 2, "Unexpected capabilities")
+	assert.Equal(t, "ietf-interfaces", ns.Schemas.Schema[0].Identifier, "Unexpected schema")
+
+	assert.Nil(t, ns.Datastore(StartupCfg).Locks, "Not expecting startup locks")
+	assert.Nil(t, ns.Datastore("other"), "Not expecting datastore")
+	assert.Equal(t, &GlobalLock{LockedBySession: 3, LockedTime: time.Date(2020, 1, 2, 2, 4, 6, 0, time.UTC)},
+		utcGlobalLock(ns.Datastore(CandidateCfg).Locks.GlobalLock), "Unexpected global lock")
+	assert.Equal(t, []PartialLock{{
+		LockID:          1,
+		LockedBySession: 4,
+		LockedTime:      time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+		Select:          []string{"/if:interfaces"},
+		LockedNodes:     []string{"/if:interfaces/if:interface[if:name='eth0']"},
+	}}, ns.Datastore(RunningCfg).Locks.PartialLocks, "Unexpected partial locks")
+
+	assert.Len(t, ns.Sessions.Session, 2, "Expected every session")
+	assert.Equal(t, NetconfSession{
+		SessionID:        3,
+		Transport:        "netconf-ssh",
+		Username:         "admin",
+		SourceHost:       "10.0.0.1",
+		LoginTime:        time.Date(2020, 1, 2, 3, 0, 0, 0, time.UTC),
+		InRpcs:           10,
+		InBadRpcs:        1,
+		OutRPCErrors:     2,
+		OutNotifications: 3,
+	}, ns.Sessions.Session[0], "Unexpected session")
+	assert.Equal(t, Statistics{
+		NetconfStartTime: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+		InBadHellos:      1,
+		InSessions:       20,
+		DroppedSessions:  2,
+		InRpcs:           300,
+		InBadRpcs:        4,
+		OutRPCErrors:     5,
+		OutNotifications: 60,
+	}, ns.Statistics, "Unexpected statistics")
+}
+
+func TestGetNetconfStateExecuteError(t *testing.T) {
+	ncs, mcli := newOpsSessionWithMockClient(t)
+	mcli.On("Execute", createGetNetconfStateRequest()).Return(nil, errors.New("failure"))
+
+	ns, err := ncs.GetNetconfState()
+	assert.Error(t, err, "Expecting call to fail")
+	assert.Nil(t, ns, "Not expecting state")
+}
+
+func TestKillStaleSessions(t *testing.T) {
+	reply := &common.RPCReply{Data: `<data><netconf-state xmlns="urn:ietf:params:xml:ns:yang:ietf-netconf-monitoring"><sessions>
+<session><session-id>1</session-id><username>admin</username><login-time>2020-01-01T00:00:00Z</login-time></session>
+<session><session-id>2</session-id><username>oper</username><login-time>2020-01-01T00:00:00Z</login-time></session>
+<session><session-id>3</session-id><username>admin</username><login-time>2020-01-03T00:00:00Z</login-time></session>
+<session><session-id>4</session-id><username>admin</username><login-time>2020-01-01T00:00:00Z</login-time></session>
+<session><session-id>5</session-id><username>admin</username><login-time>2020-01-01T00:00:00Z</login-time></session>
+</sessions></netconf-state></data>`}
+
+	ncs, mcli := newOpsSessionWithMockClient(t)
+	mcli.On("Execute", createGetNetconfStateRequest()).Return(reply, nil)
+	mcli.On("ID").Return(uint64(4))
+	mcli.On("Execute", createKillSessionRequest(1)).Return(&common.RPCReply{}, nil)
+	mcli.On("Execute", createKillSessionRequest(5)).Return(&common.RPCReply{}, nil)
+
+	killed, err := KillStaleSessions(ncs, "admin", time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC))
+	assert.NoError(t, err, "Not expecting kill to fail")
+	assert.Equal(t, []uint64{1, 5}, killed, "Unexpected sessions killed")
+	mcli.AssertExpectations(t)
+
+	ncs, mcli = newOpsSessionWithMockClient(t)
+	mcli.On("Execute", createGetNetconfStateRequest()).Return(reply, nil)
+	mcli.On("ID").Return(uint64(4))
+	mcli.On("Execute", createKillSessionRequest(1)).Return(&common.RPCReply{}, nil)
+	mcli.On("Execute", createKillSessionRequest(3)).Return(nil, errors.New("failure"))
+
+	killed, err = KillStaleSessions(ncs, "admin", time.Time{})
+	assert.EqualError(t, err, "failure", "Expecting kill to fail")
+	assert.Equal(t, []uint64{1}, killed, "Unexpected sessions killed")
+}
+
+func utcGlobalLock(lock *GlobalLock) *GlobalLock {
+	return &GlobalLock{LockedBySession: lock.LockedBySession, LockedTime: lock.LockedTime.UTC()}
+}
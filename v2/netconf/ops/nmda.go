@@ -0,0 +1,182 @@
+package ops
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/damianoneill/net/v2/netconf/common"
+)
+
+// Defines the NMDA operations described by RFC 8526.
+
+const (
+	// NmdaNS is the namespace of the NMDA operations.
+	NmdaNS = "urn:ietf:params:xml:ns:yang:ietf-netconf-nmda"
+	// DatastoresNS is the namespace of the datastore identities.
+	DatastoresNS = "urn:ietf:params:xml:ns:yang:ietf-datastores"
+	// OriginNS is the namespace of the origin identities.
+	OriginNS = "urn:ietf:params:xml:ns:yang:ietf-origin"
+
+	// Origin identities, as defined by RFC 8342.
+	OriginIntended = "intended"
+	OriginDynamic  = "dynamic"
+	OriginSystem   = "system"
+	OriginLearned  = "learned"
+	OriginDefault  = "default"
+	OriginUnknown  = "unknown"
+)
+
+// ErrDatastoreNotWritable is returned by EditData if the datastore is neither the running nor the candidate datastore.
+var ErrDatastoreNotWritable = errors.New("datastore cannot be edited")
+
+// OriginAttr is the name of the attribute that reports the origin of an operational data node when WithOrigin
+// is requested.
+var OriginAttr = xml.Name{Space: OriginNS, Local: "origin"}
+
+type SubtreeFilter struct {
+	XMLName xml.Name `xml:"subtree-filter"`
+	*common.Union
+}
+
+type GetDataReq struct {
+	XMLName             xml.Name       `xml:"urn:ietf:params:xml:ns:yang:ietf-netconf-nmda get-data"`
+	DsNS                string         `xml:"xmlns:ds,attr"`
+	OrNS                string         `xml:"xmlns:or,attr,omitempty"`
+	Datastore           string         `xml:"datastore"`
+	SubtreeFilter       *SubtreeFilter `xml:"subtree-filter"`
+	XpathFilter         string         `xml:",innerxml"`
+	ConfigFilter        *bool          `xml:"config-filter"`
+	OriginFilter        []string       `xml:"origin-filter,omitempty"`
+	NegatedOriginFilter []string       `xml:"negated-origin-filter,omitempty"`
+	MaxDepth            string         `xml:"max-depth,omitempty"`
+	WithOrigin          *struct{}      `xml:"with-origin"`
+}
+
+type EditDataReq struct {
+	XMLName          xml.Name `xml:"urn:ietf:params:xml:ns:yang:ietf-netconf-nmda edit-data"`
+	DsNS             string   `xml:"xmlns:ds,attr"`
+	Datastore        string   `xml:"datastore"`
+	DefaultOperation string   `xml:"default-operation,omitempty"`
+	Config           *Config
+	ConfigURL        string `xml:"url,omitempty"`
+	configReader     io.Reader
+}
+
+// ContentReader returns the reader that delivers the configuration content, if defined by CfgReader.
+func (r *EditDataReq) ContentReader() io.Reader {
+	return r.configReader
+}
+
+// GetDataOption qualifies a get-data operation.
+type GetDataOption func(*GetDataReq)
+
+// DataSubtree selects the data to be retrieved by a subtree filter, which can be an xml string or a struct with
+// xml tags.
+func DataSubtree(filter interface{}) GetDataOption {
+	return func(req *GetDataReq) {
+		req.SubtreeFilter = &SubtreeFilter{Union: common.GetUnion(filter)}
+	}
+}
+
+// DataXpath selects the data to be retrieved by an xpath expression, using the prefixes defined by nslist.
+func DataXpath(xpath string, nslist []Namespace) GetDataOption {
+	return func(req *GetDataReq) {
+		req.XpathFilter = createNmdaXpathFilter(xpath, nslist)
+	}
+}
+
+// ConfigFilter restricts the data retrieved to configuration (config true) or state (config false) nodes.
+func ConfigFilter(config bool) GetDataOption {
+	return func(req *GetDataReq) {
+		req.ConfigFilter = &config
+	}
+}
+
+// OriginFilter restricts the data retrieved to nodes with any of the specified origins, such as OriginIntended.
+func OriginFilter(origins ...string) GetDataOption {
+	return func(req *GetDataReq) {
+		req.OrNS = OriginNS
+		req.OriginFilter = originIdentities(origins)
+	}
+}
+
+// NegatedOriginFilter restricts the data retrieved to nodes with none of the specified origins.
+func NegatedOriginFilter(origins ...string) GetDataOption {
+	return func(req *GetDataReq) {
+		req.OrNS = OriginNS
+		req.NegatedOriginFilter = originIdentities(origins)
+	}
+}
+
+// MaxDepth limits the depth of the subtrees retrieved; zero indicates that the depth is unbounded.
+func MaxDepth(depth uint16) GetDataOption {
+	return func(req *GetDataReq) {
+		if depth == 0 {
+			req.MaxDepth = "unbounded"
+		} else {
+			req.MaxDepth = strconv.Itoa(int(depth))
+		}
+	}
+}
+
+// WithOrigin requests that the origin of each data node is reported, by the OriginAttr attribute.
+func WithOrigin() GetDataOption {
+	return func(req *GetDataReq) {
+		req.WithOrigin = &struct{}{}
+	}
+}
+
+func (s *sImpl) GetData(datastore string, result interface{}, options ...GetDataOption) error {
+	return s.handleGetRequest(createGetDataRequest(datastore, options...), result)
+}
+
+func (s *sImpl) EditData(datastore string, config ConfigOption, options ...EditOption) error {
+	if datastore != RunningCfg && datastore != CandidateCfg {
+		return fmt.Errorf("%w: %s", ErrDatastoreNotWritable, datastore)
+	}
+	_, err := s.Session.Execute(createEditDataRequest(datastore, config, options...))
+	return err
+}
+
+func createGetDataRequest(datastore string, options ...GetDataOption) *GetDataReq {
+	req := &GetDataReq{DsNS: DatastoresNS, Datastore: datastoreIdentity(datastore)}
+	for _, opt := range options {
+		opt(req)
+	}
+	return req
+}
+
+func createEditDataRequest(datastore string, cfgOpt ConfigOption, options ...EditOption) *EditDataReq {
+	// Configuration and options are defined in the same way as for edit-config.
+	ecr := createEditConfigRequest(datastore, cfgOpt, options...)
+	return &EditDataReq{
+		DsNS:             DatastoresNS,
+		Datastore:        datastoreIdentity(datastore),
+		DefaultOperation: ecr.DefaultOperation,
+		Config:           ecr.Config,
+		ConfigURL:        ecr.ConfigURL,
+		configReader:     ecr.configReader,
+	}
+}
+
+func createNmdaXpathFilter(xpath string, nslist []Namespace) string {
+	var expr strings.Builder
+	_ = xml.EscapeText(&expr, []byte(xpath))
+	return fmt.Sprintf(`<xpath-filter %s>%s</xpath-filter>`, getNamespaceAttributes(nslist), expr.String())
+}
+
+func datastoreIdentity(name string) string {
+	return "ds:" + name
+}
+
+func originIdentities(origins []string) []string {
+	ids := make([]string, len(origins))
+	for i, origin := range origins {
+		ids[i] = "or:" + origin
+	}
+	return ids
+}
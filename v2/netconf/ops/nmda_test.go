@@ -0,0 +1,89 @@
+package ops
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/damianoneill/net/v2/netconf/common"
+	"github.com/damianoneill/net/v2/netconf/testserver"
+
+	assert "github.com/stretchr/testify/require"
+)
+
+func TestGetData(t *testing.T) {
+	ncs := newOpsSessionWithTestServer(t, testserver.NewTestNetconfServer(t))
+	defer ncs.Close()
+
+	var result string
+	err := ncs.GetData(OperationalCfg, &result, DataSubtree(`<interfaces xmlns="urn:if"/>`), ConfigFilter(false),
+		OriginFilter(OriginIntended, OriginLearned), MaxDepth(3), WithOrigin())
+	assert.NoError(t, err, "Not expecting call to fail")
+	assert.Equal(t, `<datastore>ds:operational</datastore>`+
+		`<subtree-filter><interfaces xmlns="urn:if"/></subtree-filter>`+
+		`<config-filter>false</config-filter>`+
+		`<origin-filter>or:intended</origin-filter><origin-filter>or:learned</origin-filter>`+
+		`<max-depth>3</max-depth><with-origin></with-origin>`, result, "Unexpected request content")
+}
+
+func TestGetDataToStruct(t *testing.T) {
+	ncs := newOpsSessionWithTestServer(t, testserver.NewTestNetconfServer(t))
+	defer ncs.Close()
+
+	type datastore struct {
+		XMLName xml.Name `xml:"datastore"`
+		Value   string   `xml:",chardata"`
+	}
+	result := &datastore{}
+	err := ncs.GetData(IntendedCfg, result)
+	assert.NoError(t, err, "Not expecting call to fail")
+	assert.Equal(t, "ds:intended", result.Value, "Unexpected result")
+}
+
+func TestGetDataRequest(t *testing.T) {
+	b, _ := xml.Marshal(createGetDataRequest(RunningCfg,
+		DataXpath("/if:interfaces/if:interface[if:mtu < 1500]", []Namespace{{ID: "if", Path: "urn:if"}}),
+		NegatedOriginFilter(OriginSystem), MaxDepth(0)))
+	assert.Equal(t, `<get-data xmlns="urn:ietf:params:xml:ns:yang:ietf-netconf-nmda" `+
+		`xmlns:ds="urn:ietf:params:xml:ns:yang:ietf-datastores" xmlns:or="urn:ietf:params:xml:ns:yang:ietf-origin">`+
+		`<datastore>ds:running</datastore>`+
+		`<xpath-filter xmlns:if="urn:if">/if:interfaces/if:interface[if:mtu &lt; 1500]</xpath-filter>`+
+		`<negated-origin-filter>or:system</negated-origin-filter>`+
+		`<max-depth>unbounded</max-depth></get-data>`, string(b), "Unexpected request")
+}
+
+func TestEditData(t *testing.T) {
+	ncs, mcli := newOpsSessionWithMockClient(t)
+	mcli.On("Execute", createEditDataRequest(RunningCfg, Cfg(`<top/>`), DefaultOperation(ReplaceOp))).Return(&common.RPCReply{}, nil)
+
+	err := ncs.EditData(RunningCfg, Cfg(`<top/>`), DefaultOperation(ReplaceOp))
+	assert.NoError(t, err, "Not expecting call to fail")
+
+	mcli.AssertExpectations(t)
+
+	b, _ := xml.Marshal(createEditDataRequest(CandidateCfg, Cfg(`<top/>`), DefaultOperation(ReplaceOp)))
+	assert.Equal(t, `<edit-data xmlns="urn:ietf:params:xml:ns:yang:ietf-netconf-nmda" `+
+		`xmlns:ds="urn:ietf:params:xml:ns:yang:ietf-datastores"><datastore>ds:candidate</datastore>`+
+		`<default-operation>replace</default-operation><config><top/></config></edit-data>`, string(b), "Unexpected request")
+}
+
+func TestEditDataInvalidDatastore(t *testing.T) {
+	ncs, mcli := newOpsSessionWithMockClient(t)
+
+	for _, ds := range []string{OperationalCfg, IntendedCfg, StartupCfg} {
+		err := ncs.EditData(ds, Cfg(`<top/>`))
+		assert.ErrorIs(t, err, ErrDatastoreNotWritable, "Expecting edit of %s to fail", ds)
+	}
+	mcli.AssertNotCalled(t, "Execute")
+}
+
+func TestEditDataReader(t *testing.T) {
+	ts := testserver.NewTestNetconfServer(t)
+	ncs := newOpsSessionWithTestServer(t, ts)
+	defer ncs.Close()
+
+	err := ncs.EditData(RunningCfg, CfgReader(strings.NewReader(`<top><sub/></top>`)))
+	assert.NoError(t, err, "Not expecting call to fail")
+	assert.Equal(t, `<datastore>ds:running</datastore><config><top><sub/></top></config>`,
+		ts.SessionHandler(ncs.ID()).LastReq().Body, "Unexpected request content")
+}
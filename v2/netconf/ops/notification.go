@@ -0,0 +1,170 @@
+package ops
+
+import (
+	"encoding/xml"
+	"time"
+
+	"github.com/damianoneill/net/v2/netconf/common"
+)
+
+// Defines the event notification operations described by RFC 5277.
+
+const (
+	// CapNotification is the notification capability, as advertised by a server that supports create-subscription.
+	CapNotification = "urn:ietf:params:netconf:capability:notification:1.0"
+	// CapInterleave is the interleave capability, as advertised by a server that accepts requests on a session
+	// with an active subscription.
+	CapInterleave = "urn:ietf:params:netconf:capability:interleave:1.0"
+	// NetmodNotificationNS is the namespace of the stream discovery model and the subscription control events.
+	NetmodNotificationNS = "urn:ietf:params:xml:ns:netmod:notification"
+
+	// The default event stream.
+	NetconfStream = "NETCONF"
+)
+
+// Define the names of the events that report the progress of a subscription.
+var (
+	ReplayCompleteEvent       = xml.Name{Space: NetmodNotificationNS, Local: "replayComplete"}
+	NotificationCompleteEvent = xml.Name{Space: NetmodNotificationNS, Local: "notificationComplete"}
+)
+
+// subscriptionBufferSize defines the number of notifications that can be buffered for a subscription before they
+// are dropped by the session.
+const subscriptionBufferSize = 64
+
+type CreateSubscriptionReq struct {
+	XMLName    xml.Name `xml:"urn:ietf:params:xml:ns:netconf:notification:1.0 create-subscription"`
+	Stream     string   `xml:"stream,omitempty"`
+	Filter     *Filter
+	FilterBody string `xml:",innerxml"`
+	StartTime  string `xml:"startTime,omitempty"`
+	StopTime   string `xml:"stopTime,omitempty"`
+}
+
+// Stream describes an event stream supported by a server.
+type Stream struct {
+	Name                  string    `xml:"name"`
+	Description           string    `xml:"description"`
+	ReplaySupport         bool      `xml:"replaySupport"`
+	ReplayLogCreationTime time.Time `xml:"replayLogCreationTime"`
+	ReplayLogAgedTime     time.Time `xml:"replayLogAgedTime"`
+}
+
+type netmodNetconf struct {
+	XMLName xml.Name `xml:"urn:ietf:params:xml:ns:netmod:notification netconf"`
+	Streams []Stream `xml:"streams>stream"`
+}
+
+// NotificationFilter selects the events delivered by a subscription.
+type NotificationFilter func(*CreateSubscriptionReq)
+
+// NotificationSubtree selects the events delivered by a subscription by a subtree filter, which can be an xml string
+// or a struct with xml tags.
+func NotificationSubtree(filter interface{}) NotificationFilter {
+	return func(req *CreateSubscriptionReq) {
+		req.Filter = &Filter{Type: "subtree", Union: common.GetUnion(filter)}
+	}
+}
+
+// NotificationXpath selects the events delivered by a subscription by an xpath expression, using the prefixes
+// defined by nslist. The server must support the :xpath capability.
+func NotificationXpath(xpath string, nslist []Namespace) NotificationFilter {
+	return func(req *CreateSubscriptionReq) {
+		req.FilterBody = createXpathFilter(xpath, nslist)
+	}
+}
+
+// Subscription delivers the notifications of an event stream subscription.
+type Subscription struct {
+	notifications  chan *common.Notification
+	replayComplete chan struct{}
+	complete       bool
+}
+
+// Notifications returns the channel that delivers the events received by the subscription.
+// The channel is closed when the server reports that the subscription is complete, as it does once the stop time
+// is reached, or when the session is closed.
+func (s *Subscription) Notifications() <-chan *common.Notification {
+	return s.notifications
+}
+
+// ReplayComplete returns a channel that is closed when the server reports that the replay of stored events
+// is complete, or when the subscription ends without the replay being reported as complete.
+func (s *Subscription) ReplayComplete() <-chan struct{} {
+	return s.replayComplete
+}
+
+// Complete reports whether the server has reported that the subscription is complete; it should only be called
+// once the notifications channel has been closed.
+func (s *Subscription) Complete() bool {
+	return s.complete
+}
+
+func (s *sImpl) CreateSubscription(stream string, filter NotificationFilter, startTime, stopTime time.Time) (*Subscription, error) {
+	if err := s.requireCapability(CapNotification); err != nil {
+		return nil, err
+	}
+	nchan := make(chan *common.Notification, subscriptionBufferSize)
+	if _, err := s.Session.Subscribe(createCreateSubscriptionRequest(stream, filter, startTime, stopTime), nchan); err != nil {
+		return nil, err
+	}
+
+	sub := &Subscription{
+		notifications:  make(chan *common.Notification),
+		replayComplete: make(chan struct{}),
+	}
+	go sub.deliver(nchan)
+	return sub, nil
+}
+
+func (s *sImpl) GetStreams() ([]Stream, error) {
+	result := &netmodNetconf{}
+	if err := s.handleGetRequest(createGetStreamsRequest(), result); err != nil {
+		return nil, err
+	}
+	return result.Streams, nil
+}
+
+// deliver forwards notifications received by the session until the subscription is complete, or the session closed.
+func (s *Subscription) deliver(nchan chan *common.Notification) {
+	replaying := true
+	defer func() {
+		if replaying {
+			close(s.replayComplete)
+		}
+		close(s.notifications)
+	}()
+
+	for n := range nchan {
+		switch n.XMLName {
+		case ReplayCompleteEvent:
+			if replaying {
+				replaying = false
+				close(s.replayComplete)
+			}
+		case NotificationCompleteEvent:
+			s.complete = true
+			return
+		default:
+			s.notifications <- n
+		}
+	}
+}
+
+func createCreateSubscriptionRequest(stream string, filter NotificationFilter, startTime, stopTime time.Time) *CreateSubscriptionReq {
+	req := &CreateSubscriptionReq{Stream: stream}
+	if filter != nil {
+		filter(req)
+	}
+	if !startTime.IsZero() {
+		req.StartTime = startTime.Format(time.RFC3339Nano)
+	}
+	if !stopTime.IsZero() {
+		req.StopTime = stopTime.Format(time.RFC3339Nano)
+	}
+	return req
+}
+
+func createGetStreamsRequest() *GetReq {
+	return createGetSubtreeRequest(`<netconf xmlns="urn:ietf:params:xml:ns:netmod:notification"><streams/></netconf>`)
+}
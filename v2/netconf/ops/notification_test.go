@@ -0,0 +1,166 @@
+package ops
+
+import (
+	"encoding/xml"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/damianoneill/net/v2/netconf/common"
+	"github.com/damianoneill/net/v2/netconf/testserver"
+
+	assert "github.com/stretchr/testify/require"
+)
+
+const (
+	testEventXML            = `<event xmlns="urn:test"><id>1</id></event>`
+	replayCompleteXML       = `<replayComplete xmlns="urn:ietf:params:xml:ns:netmod:notification"/>`
+	notificationCompleteXML = `<notificationComplete xmlns="urn:ietf:params:xml:ns:netmod:notification"/>`
+)
+
+func TestCreateSubscription(t *testing.T) {
+	ts := testserver.NewTestNetconfServer(t)
+	ncs := newOpsSessionWithTestServer(t, ts)
+	defer ncs.Close()
+
+	start := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	stop := start.Add(time.Hour)
+	sub, err := ncs.CreateSubscription("NETCONF", NotificationSubtree(`<event xmlns="urn:test"/>`), start, stop)
+	assert.NoError(t, err, "Not expecting subscription to fail")
+
+	sh := ts.SessionHandler(ncs.ID())
+	assert.Equal(t, `<stream>NETCONF</stream><filter type="subtree"><event xmlns="urn:test"/></filter>`+
+		`<startTime>2020-01-02T03:04:05Z</startTime><stopTime>2020-01-02T04:04:05Z</stopTime>`, sh.LastReq().Body,
+		"Unexpected request content")
+
+	sh.SendNotification(testEventXML).SendNotification(replayCompleteXML).SendNotification(testEventXML).
+		SendNotification(notificationCompleteXML).SendNotification(testEventXML)
+
+	n := <-sub.Notifications()
+	assert.Equal(t, xml.Name{Space: "urn:test", Local: "event"}, n.XMLName, "Unexpected event")
+	assert.NotNil(t, <-sub.Notifications(), "Expected event after replay")
+	select {
+	case <-sub.ReplayComplete():
+	default:
+		assert.Fail(t, "Expected replay to be complete")
+	}
+
+	_, ok := <-sub.Notifications()
+	assert.False(t, ok, "Expected subscription to be complete")
+	assert.True(t, sub.Complete(), "Expected subscription to be complete")
+}
+
+func TestCreateSubscriptionSessionClosed(t *testing.T) {
+	ts := testserver.NewTestNetconfServer(t)
+	ncs := newOpsSessionWithTestServer(t, ts)
+
+	sub, err := ncs.CreateSubscription("", nil, time.Time{}, time.Time{})
+	assert.NoError(t, err, "Not expecting subscription to fail")
+	assert.Equal(t, ``, ts.SessionHandler(ncs.ID()).LastReq().Body, "Unexpected request content")
+
+	ncs.Close()
+	_, ok := <-sub.Notifications()
+	assert.False(t, ok, "Expected notifications to be closed")
+	assert.False(t, sub.Complete(), "Not expecting subscription to be complete")
+	select {
+	case <-sub.ReplayComplete():
+	default:
+		assert.Fail(t, "Expected replay complete to be closed")
+	}
+}
+
+func TestCreateSubscriptionCompleteBeforeReplay(t *testing.T) {
+	ts := testserver.NewTestNetconfServer(t)
+	ncs := newOpsSessionWithTestServer(t, ts)
+	defer ncs.Close()
+
+	start := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	sub, err := ncs.CreateSubscription("", nil, start, start.Add(time.Minute))
+	assert.NoError(t, err, "Not expecting subscription to fail")
+
+	ts.SessionHandler(ncs.ID()).SendNotification(testEventXML).SendNotification(notificationCompleteXML)
+	assert.NotNil(t, <-sub.Notifications(), "Expected replayed event")
+	_, ok := <-sub.Notifications()
+	assert.False(t, ok, "Expected subscription to be complete")
+	select {
+	case <-sub.ReplayComplete():
+	default:
+		assert.Fail(t, "Expected replay complete to be closed")
+	}
+}
+
+func TestCreateSubscriptionFailure(t *testing.T) {
+	ts := testserver.NewTestNetconfServer(t).WithRequestHandler(testserver.FailingRequestHandler)
+	ncs := newOpsSessionWithTestServer(t, ts)
+	defer ncs.Close()
+
+	sub, err := ncs.CreateSubscription("", nil, time.Time{}, time.Time{})
+	assert.Error(t, err, "Expecting subscription to fail")
+	assert.Nil(t, sub, "Not expecting subscription")
+}
+
+func TestCreateSubscriptionRejected(t *testing.T) {
+	ts := testserver.NewTestNetconfServer(t).
+		WithRequestHandler(testserver.EchoRequestHandler).WithRequestHandler(testserver.FailingRequestHandler)
+	ncs := newOpsSessionWithTestServer(t, ts)
+
+	sub, err := ncs.CreateSubscription("", nil, time.Time{}, time.Time{})
+	assert.NoError(t, err, "Not expecting subscription to fail")
+	_, err = ncs.CreateSubscription("", nil, time.Time{}, time.Time{})
+	assert.Error(t, err, "Expecting second subscription to be rejected")
+
+	ts.SessionHandler(ncs.ID()).SendNotification(testEventXML)
+	assert.NotNil(t, <-sub.Notifications(), "Expected event on first subscription")
+
+	ncs.Close()
+	_, ok := <-sub.Notifications()
+	assert.False(t, ok, "Expected notifications to be closed")
+}
+
+func TestCreateSubscriptionRequest(t *testing.T) {
+	b, _ := xml.Marshal(createCreateSubscriptionRequest("events",
+		NotificationXpath("/t:event[t:id > 1]", []Namespace{{ID: "t", Path: "urn:test"}}), time.Time{}, time.Time{}))
+	assert.Equal(t, `<create-subscription xmlns="urn:ietf:params:xml:ns:netconf:notification:1.0">`+
+		`<stream>events</stream><filter xmlns:t="urn:test" type="xpath" select="/t:event[t:id > 1]"/>`+
+		`</create-subscription>`, string(b), "Unexpected request")
+}
+
+func TestGetStreams(t *testing.T) {
+	ncs, mcli := newOpsSessionWithMockClient(t)
+
+	mcli.On("Execute", createGetStreamsRequest()).Return(&common.RPCReply{Data: `
+	<data>
+	<netconf xmlns="urn:ietf:params:xml:ns:netmod:notification">
+	<streams>
+	<stream>
+	<name>NETCONF</name>
+	<description>default NETCONF event stream</description>
+	<replaySupport>true</replaySupport>
+	<replayLogCreationTime>2020-01-02T03:04:05Z</replayLogCreationTime>
+	</stream>
+	<stream>
+	<name>SNMP</name>
+	<description>SNMP notifications</description>
+	<replaySupport>false</replaySupport>
+	</stream>
+	</streams>
+	</netconf>
+	</data>`}, nil)
+
+	streams, err := ncs.GetStreams()
+	assert.NoError(t, err, "Not expecting call to fail")
+	assert.Equal(t, []Stream{
+		{Name: "NETCONF", Description: "default NETCONF event stream", ReplaySupport: true,
+			ReplayLogCreationTime: time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)},
+		{Name: "SNMP", Description: "SNMP notifications"},
+	}, streams, "Unexpected streams")
+}
+
+func TestGetStreamsExecuteError(t *testing.T) {
+	ncs, mcli := newOpsSessionWithMockClient(t)
+	mcli.On("Execute", createGetStreamsRequest()).Return(nil, errors.New("failure"))
+
+	streams, err := ncs.GetStreams()
+	assert.Error(t, err, "Expecting call to fail")
+	assert.Nil(t, streams, "Not expecting streams")
+}
@@ -0,0 +1,77 @@
+package ops
+
+import (
+	"encoding/xml"
+
+	"github.com/damianoneill/net/v2/netconf/common"
+)
+
+// Defines the partial lock operations described by RFC 5717.
+
+// PartialLockNS is the namespace of the partial lock operations.
+const PartialLockNS = "urn:ietf:params:xml:ns:netconf:partial-lock:1.0"
+
+// PartialLockReq defines the partial-lock operation.
+type PartialLockReq struct {
+	XMLName xml.Name     `xml:"urn:ietf:params:xml:ns:netconf:partial-lock:1.0 partial-lock"`
+	Select  []LockSelect `xml:"select"`
+}
+
+// LockSelect defines an xpath expression that selects the nodes to be locked, with the declarations of its
+// namespace prefixes.
+type LockSelect struct {
+	Namespaces []xml.Attr `xml:",any,attr"`
+	XPath      string     `xml:",chardata"`
+}
+
+// PartialLockResult holds the output of a partial-lock operation.
+type PartialLockResult struct {
+	LockID uint32 `xml:"lock-id"`
+	// The instance-identifiers of the nodes that are locked.
+	LockedNodes []string `xml:"locked-node"`
+}
+
+// PartialUnlockReq defines the partial-unlock operation.
+type PartialUnlockReq struct {
+	XMLName xml.Name `xml:"urn:ietf:params:xml:ns:netconf:partial-lock:1.0 partial-unlock"`
+	LockID  uint32   `xml:"lock-id"`
+}
+
+func (s *sImpl) PartialLock(selects []string, nslist []Namespace) (*PartialLockResult, error) {
+	if err := s.requireCapability(common.CapPartialLock); err != nil {
+		return nil, err
+	}
+	reply, err := s.Session.Execute(createPartialLockRequest(selects, nslist))
+	if err != nil {
+		return nil, err
+	}
+	lock := &PartialLockResult{}
+	if err = decodeRPCOutput(reply.Data, lock); err != nil {
+		return nil, err
+	}
+	return lock, nil
+}
+
+func (s *sImpl) PartialUnlock(lockID uint32) error {
+	if err := s.requireCapability(common.CapPartialLock); err != nil {
+		return err
+	}
+	_, err := s.Session.Execute(createPartialUnlockRequest(lockID))
+	return err
+}
+
+func createPartialLockRequest(selects []string, nslist []Namespace) *PartialLockReq {
+	var nsAttrs []xml.Attr
+	for _, ns := range nslist {
+		nsAttrs = append(nsAttrs, xml.Attr{Name: xml.Name{Local: "xmlns:" + ns.ID}, Value: ns.Path})
+	}
+	req := &PartialLockReq{}
+	for _, sel := range selects {
+		req.Select = append(req.Select, LockSelect{Namespaces: nsAttrs, XPath: sel})
+	}
+	return req
+}
+
+func createPartialUnlockRequest(lockID uint32) *PartialUnlockReq {
+	return &PartialUnlockReq{LockID: lockID}
+}
@@ -0,0 +1,72 @@
+package ops
+
+import (
+	"encoding/xml"
+	"testing"
+
+	"github.com/damianoneill/net/v2/netconf/common"
+	"github.com/damianoneill/net/v2/netconf/common/netconferrors"
+	"github.com/damianoneill/net/v2/netconf/mocks"
+
+	assert "github.com/stretchr/testify/require"
+)
+
+func TestPartialLockRequest(t *testing.T) {
+	req := createPartialLockRequest([]string{"/if:interfaces/if:interface[if:name='eth0']", "/sys:system"},
+		[]Namespace{{ID: "if", Path: "urn:ietf:params:xml:ns:yang:ietf-interfaces"}, {ID: "sys", Path: "urn:sys"}})
+	b, err := xml.Marshal(req)
+	assert.NoError(t, err, "Not expecting marshal to fail")
+	assert.Equal(t, `<partial-lock xmlns="urn:ietf:params:xml:ns:netconf:partial-lock:1.0">`+
+		`<select xmlns:if="urn:ietf:params:xml:ns:yang:ietf-interfaces" xmlns:sys="urn:sys">`+
+		`/if:interfaces/if:interface[if:name=&#39;eth0&#39;]</select>`+
+		`<select xmlns:if="urn:ietf:params:xml:ns:yang:ietf-interfaces" xmlns:sys="urn:sys">/sys:system</select>`+
+		`</partial-lock>`, string(b), "Unexpected request")
+
+	b, _ = xml.Marshal(createPartialUnlockRequest(127))
+	assert.Equal(t, `<partial-unlock xmlns="urn:ietf:params:xml:ns:netconf:partial-lock:1.0"><lock-id>127</lock-id>`+
+		`</partial-unlock>`, string(b), "Unexpected request")
+}
+
+func TestPartialLock(t *testing.T) {
+	ncs, mcli := newOpsSessionWithMockClient(t)
+	nslist := []Namespace{{ID: "if", Path: "urn:ietf:params:xml:ns:yang:ietf-interfaces"}}
+	mcli.On("Execute", createPartialLockRequest([]string{"/if:interfaces"}, nslist)).Return(&common.RPCReply{
+		Data: `<lock-id xmlns="urn:ietf:params:xml:ns:netconf:partial-lock:1.0">127</lock-id>` +
+			`<locked-node xmlns="urn:ietf:params:xml:ns:netconf:partial-lock:1.0" ` +
+			`xmlns:if="urn:ietf:params:xml:ns:yang:ietf-interfaces">/if:interfaces/if:interface[if:name='eth0']</locked-node>` +
+			`<locked-node xmlns="urn:ietf:params:xml:ns:netconf:partial-lock:1.0" ` +
+			`xmlns:if="urn:ietf:params:xml:ns:yang:ietf-interfaces">/if:interfaces/if:interface[if:name='eth1']</locked-node>`,
+	}, nil)
+	mcli.On("Execute", createPartialLockRequest([]string{"/if:interfaces/if:interface"}, nslist)).Return(nil,
+		&common.RPCErrors{Errors: []common.RPCError{{Type: "protocol", Tag: "lock-denied", Severity: "error",
+			ErrorInfo: &common.ErrorInfo{SessionID: 42}}}})
+	mcli.On("Execute", createPartialUnlockRequest(127)).Return(&common.RPCReply{Data: `<ok/>`}, nil)
+
+	lock, err := ncs.PartialLock([]string{"/if:interfaces"}, nslist)
+	assert.NoError(t, err, "Not expecting partial lock to fail")
+	assert.Equal(t, &PartialLockResult{LockID: 127, LockedNodes: []string{
+		"/if:interfaces/if:interface[if:name='eth0']",
+		"/if:interfaces/if:interface[if:name='eth1']",
+	}}, lock, "Unexpected lock")
+
+	lock, err = ncs.PartialLock([]string{"/if:interfaces/if:interface"}, nslist)
+	assert.ErrorIs(t, err, netconferrors.ErrLockDenied, "Expecting partial lock to be denied")
+	assert.Nil(t, lock, "Not expecting lock")
+	holder, ok := common.LockHolder(err)
+	assert.True(t, ok, "Expecting lock holder")
+	assert.Equal(t, uint64(42), holder, "Unexpected lock holder")
+
+	assert.NoError(t, ncs.PartialUnlock(127), "Not expecting partial unlock to fail")
+	mcli.AssertExpectations(t)
+}
+
+func TestPartialLockCapability(t *testing.T) {
+	mcli := &mocks.OpSession{}
+	ncs := &sImpl{Session: mcli, checkCaps: true}
+	mcli.On("ServerCapabilities").Return([]string{common.CapBase11})
+
+	_, err := ncs.PartialLock([]string{"/top"}, nil)
+	assert.ErrorIs(t, err, ErrCapabilityNotSupported, "Expecting partial lock to fail")
+	assert.ErrorIs(t, ncs.PartialUnlock(1), ErrCapabilityNotSupported, "Expecting partial unlock to fail")
+	mcli.AssertNotCalled(t, "Execute")
+}
@@ -0,0 +1,164 @@
+package ops
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/damianoneill/net/v2/netconf/common"
+)
+
+// Defines the invocation of YANG rpcs and actions (RFC 7950 sections 7.14 and 7.15).
+
+// YangNS is the namespace of the YANG action element.
+const YangNS = "urn:ietf:params:xml:ns:yang:1"
+
+// rpcReq defines a request for a YANG rpc.
+type rpcReq struct {
+	name  xml.Name
+	input interface{}
+}
+
+// MarshalXML implements xml.Marshaler.
+func (r *rpcReq) MarshalXML(e *xml.Encoder, _ xml.StartElement) error {
+	return encodeOperation(e, xml.StartElement{Name: r.name}, r.input)
+}
+
+// actionReq defines a request for a YANG action.
+type actionReq struct {
+	path  *FilterNode
+	input interface{}
+}
+
+// MarshalXML implements xml.Marshaler.
+func (r *actionReq) MarshalXML(e *xml.Encoder, _ xml.StartElement) error {
+	start := xml.StartElement{Name: xml.Name{Space: YangNS, Local: "action"}}
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	if err := r.path.encodeAction(e, YangNS, r.input); err != nil {
+		return err
+	}
+	return e.EncodeToken(start.End())
+}
+
+// encodeAction encodes the node, with the input as the content of the action node, which is reached by following
+// the last child of each node.
+func (n *FilterNode) encodeAction(e *xml.Encoder, parentNS string, input interface{}) error {
+	start, ns := n.startElement(parentNS)
+	if len(n.children) == 0 {
+		return encodeOperation(e, start, input)
+	}
+
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	last := len(n.children) - 1
+	for _, child := range n.children[:last] {
+		if err := child.encode(e, ns); err != nil {
+			return err
+		}
+	}
+	if err := n.children[last].encodeAction(e, ns, input); err != nil {
+		return err
+	}
+	return e.EncodeToken(start.End())
+}
+
+// encodeOperation encodes the element that invokes an rpc or action, with the input parameters as its content.
+func encodeOperation(e *xml.Encoder, start xml.StartElement, input interface{}) error {
+	switch v := input.(type) {
+	case nil:
+		if err := e.EncodeToken(start); err != nil {
+			return err
+		}
+		return e.EncodeToken(start.End())
+	case string:
+		return e.EncodeElement(struct {
+			Content string `xml:",innerxml"`
+		}{v}, start)
+	default:
+		return e.EncodeElement(v, start)
+	}
+}
+
+func (s *sImpl) InvokeRPC(name, namespace string, input, output interface{}) error {
+	if err := s.requireModule(namespace); err != nil {
+		return err
+	}
+	return s.handleOperationRequest(createRPCRequest(name, namespace, input), output)
+}
+
+func (s *sImpl) InvokeAction(path *FilterNode, input, output interface{}) error {
+	if path == nil {
+		return errors.New("action path is not defined")
+	}
+	if err := s.requireCapability(common.CapYangLibrary10, common.CapYangLibrary11); err != nil {
+		return err
+	}
+	return s.handleOperationRequest(createActionRequest(path, input), output)
+}
+
+// requireModule returns an error if capability checks are enabled and the server neither advertises the module
+// with the namespace, nor a YANG library that can define it.
+func (s *sImpl) requireModule(namespace string) error {
+	if !s.checkCaps {
+		return nil
+	}
+	caps := common.ParseCapabilities(s.ServerCapabilities())
+	for _, m := range caps.Modules() {
+		if m.Namespace == namespace {
+			return nil
+		}
+	}
+	if caps.HasCapability(common.CapYangLibrary10, common.CapYangLibrary11) {
+		return nil
+	}
+	return fmt.Errorf("%w: module %s", ErrCapabilityNotSupported, namespace)
+}
+
+// handleOperationRequest executes an rpc or action request, and decodes its output parameters into output, unless
+// the reply is ok.
+func (s *sImpl) handleOperationRequest(req common.Request, output interface{}) error {
+	reply, err := s.Session.Execute(req)
+	if err != nil || output == nil || isOkReply(reply.Data) {
+		return err
+	}
+
+	if target, ok := output.(*string); ok {
+		*target = strings.TrimSpace(reply.Data)
+		return nil
+	}
+	return decodeRPCOutput(reply.Data, output)
+}
+
+// isOkReply reports whether the content of an rpc-reply is an ok element.
+func isOkReply(data string) bool {
+	d := xml.NewDecoder(strings.NewReader(data))
+	for {
+		token, err := d.Token()
+		if err != nil {
+			return false
+		}
+		start, ok := token.(xml.StartElement)
+		switch {
+		case !ok:
+		case start.Name.Local == "rpc-error":
+			// A warning.
+			if err = d.Skip(); err != nil {
+				return false
+			}
+		default:
+			return start.Name.Local == "ok" && (start.Name.Space == "" || start.Name.Space == common.NetconfNS)
+		}
+	}
+}
+
+func createRPCRequest(name, namespace string, input interface{}) *rpcReq {
+	return &rpcReq{name: xml.Name{Space: namespace, Local: name}, input: input}
+}
+
+func createActionRequest(path *FilterNode, input interface{}) *actionReq {
+	return &actionReq{path: path, input: input}
+}
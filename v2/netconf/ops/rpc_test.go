@@ -0,0 +1,122 @@
+package ops
+
+import (
+	"encoding/xml"
+	"errors"
+	"testing"
+
+	"github.com/damianoneill/net/v2/netconf/common"
+	"github.com/damianoneill/net/v2/netconf/mocks"
+
+	assert "github.com/stretchr/testify/require"
+)
+
+type pingInput struct {
+	XMLName     xml.Name `xml:"ignored"`
+	Destination string   `xml:"destination"`
+	Count       int      `xml:"count,omitempty"`
+}
+
+type pingOutput struct {
+	Sent     int `xml:"sent"`
+	Received int `xml:"received"`
+}
+
+func TestRPCRequest(t *testing.T) {
+	b, err := xml.Marshal(createRPCRequest("ping", "urn:vendor:ping", &pingInput{Destination: "10.0.0.1", Count: 3}))
+	assert.NoError(t, err, "Not expecting marshal to fail")
+	assert.Equal(t, `<ping xmlns="urn:vendor:ping"><destination>10.0.0.1</destination><count>3</count></ping>`,
+		string(b), "Unexpected request")
+
+	b, _ = xml.Marshal(createRPCRequest("ping", "urn:vendor:ping", `<destination>10.0.0.1</destination>`))
+	assert.Equal(t, `<ping xmlns="urn:vendor:ping"><destination>10.0.0.1</destination></ping>`, string(b),
+		"Unexpected request")
+
+	b, _ = xml.Marshal(createRPCRequest("reboot", "urn:vendor:system", nil))
+	assert.Equal(t, `<reboot xmlns="urn:vendor:system"></reboot>`, string(b), "Unexpected request")
+}
+
+func TestActionRequest(t *testing.T) {
+	path := Container("interfaces",
+		Container("interface", Match("name", "eth0"), Select("reset"))).Namespace("urn:vendor:interfaces")
+	b, err := xml.Marshal(createActionRequest(path, `<delay>5</delay>`))
+	assert.NoError(t, err, "Not expecting marshal to fail")
+	assert.Equal(t, `<action xmlns="urn:ietf:params:xml:ns:yang:1"><interfaces xmlns="urn:vendor:interfaces">`+
+		`<interface><name>eth0</name><reset><delay>5</delay></reset></interface></interfaces></action>`,
+		string(b), "Unexpected request")
+
+	b, _ = xml.Marshal(createActionRequest(Select("reset").Namespace("urn:vendor:system"), nil))
+	assert.Equal(t, `<action xmlns="urn:ietf:params:xml:ns:yang:1"><reset xmlns="urn:vendor:system"></reset></action>`,
+		string(b), "Unexpected request")
+}
+
+func TestInvokeRPC(t *testing.T) {
+	ncs, mcli := newOpsSessionWithMockClient(t)
+	input := &pingInput{Destination: "10.0.0.1"}
+	mcli.On("Execute", createRPCRequest("ping", "urn:vendor:ping", input)).Return(&common.RPCReply{
+		Data: `<sent xmlns="urn:vendor:ping">3</sent><received xmlns="urn:vendor:ping">2</received>`,
+	}, nil)
+	mcli.On("Execute", createRPCRequest("reboot", "urn:vendor:system", nil)).Return(&common.RPCReply{Data: `<ok/>`}, nil)
+	mcli.On("Execute", createRPCRequest("fail", "urn:vendor:system", nil)).Return(nil,
+		&common.RPCErrors{Errors: []common.RPCError{{Tag: "operation-failed", Severity: "error"}}})
+
+	output := &pingOutput{}
+	assert.NoError(t, ncs.InvokeRPC("ping", "urn:vendor:ping", input, output), "Not expecting rpc to fail")
+	assert.Equal(t, &pingOutput{Sent: 3, Received: 2}, output, "Unexpected output")
+
+	var raw string
+	assert.NoError(t, ncs.InvokeRPC("ping", "urn:vendor:ping", input, &raw), "Not expecting rpc to fail")
+	assert.Equal(t, `<sent xmlns="urn:vendor:ping">3</sent><received xmlns="urn:vendor:ping">2</received>`, raw,
+		"Unexpected output")
+
+	output = &pingOutput{}
+	assert.NoError(t, ncs.InvokeRPC("reboot", "urn:vendor:system", nil, output), "Not expecting rpc to fail")
+	assert.Equal(t, &pingOutput{}, output, "Not expecting output")
+	assert.NoError(t, ncs.InvokeRPC("reboot", "urn:vendor:system", nil, nil), "Not expecting rpc to fail")
+
+	err := ncs.InvokeRPC("fail", "urn:vendor:system", nil, nil)
+	var rpcErrs *common.RPCErrors
+	assert.True(t, errors.As(err, &rpcErrs), "Expecting rpc errors")
+	mcli.AssertExpectations(t)
+}
+
+func TestInvokeAction(t *testing.T) {
+	ncs, mcli := newOpsSessionWithMockClient(t)
+	path := Container("interfaces",
+		Container("interface", Match("name", "eth0"), Select("statistics"))).Namespace("urn:vendor:interfaces")
+	mcli.On("Execute", createActionRequest(path, nil)).Return(&common.RPCReply{
+		Data: `<rpc-error><error-severity>warning</error-severity></rpc-error>` +
+			`<in-octets xmlns="urn:vendor:interfaces">100</in-octets>`,
+	}, nil)
+
+	output := &struct {
+		InOctets uint64 `xml:"in-octets"`
+	}{}
+	assert.NoError(t, ncs.InvokeAction(path, nil, output), "Not expecting action to fail")
+	assert.Equal(t, uint64(100), output.InOctets, "Unexpected output")
+	assert.Error(t, ncs.InvokeAction(nil, nil, output), "Expecting action to fail")
+	mcli.AssertExpectations(t)
+}
+
+func TestInvokeCapabilities(t *testing.T) {
+	mcli := &mocks.OpSession{}
+	ncs := &sImpl{Session: mcli, checkCaps: true}
+	mcli.On("ServerCapabilities").Return([]string{common.CapBase11,
+		"urn:vendor:ping?module=vendor-ping&revision=2020-01-01"})
+	mcli.On("Execute", createRPCRequest("ping", "urn:vendor:ping", nil)).Return(&common.RPCReply{Data: `<ok/>`}, nil)
+
+	assert.NoError(t, ncs.InvokeRPC("ping", "urn:vendor:ping", nil, nil), "Not expecting rpc to fail")
+	err := ncs.InvokeRPC("reboot", "urn:vendor:system", nil, nil)
+	assert.ErrorIs(t, err, ErrCapabilityNotSupported, "Expecting rpc to fail")
+	assert.EqualError(t, err, "capability not supported by server: module urn:vendor:system", "Unexpected error")
+	err = ncs.InvokeAction(Select("reset").Namespace("urn:vendor:ping"), nil, nil)
+	assert.ErrorIs(t, err, ErrCapabilityNotSupported, "Expecting action to fail")
+
+	mcli = &mocks.OpSession{}
+	ncs = &sImpl{Session: mcli, checkCaps: true}
+	mcli.On("ServerCapabilities").Return([]string{common.CapBase11, common.CapYangLibrary11 + "?content-id=1"})
+	mcli.On("Execute", createRPCRequest("reboot", "urn:vendor:system", nil)).Return(&common.RPCReply{Data: `<ok/>`}, nil)
+	mcli.On("Execute", createActionRequest(Select("reset"), nil)).Return(&common.RPCReply{Data: `<ok/>`}, nil)
+	assert.NoError(t, ncs.InvokeRPC("reboot", "urn:vendor:system", nil, nil), "Not expecting rpc to fail")
+	assert.NoError(t, ncs.InvokeAction(Select("reset"), nil, nil), "Not expecting action to fail")
+}
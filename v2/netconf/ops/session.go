@@ -1,9 +1,12 @@
 package ops
 
 import (
+	"context"
 	"encoding/xml"
 	"fmt"
+	"io"
 	"strings"
+	"time"
 
 	"github.com/damianoneill/net/v2/netconf/client"
 
@@ -23,39 +26,95 @@ type OpSession interface {
 	// should be the address of either:
 	// - a string, in which case it will hold the response body, or
 	// - a struct with xml tags.
-	GetSubtree(filter interface{}, result interface{}) error
+	// The filter can be built with Container, Select and Match, or derived from the result by StructFilter.
+	// GetOptions can be added to qualify the operation.
+	GetSubtree(filter interface{}, result interface{}, options ...GetOption) error
 
 	// GetXpath issues a GET request, with the supplied xpath filter and namespace list and stores the response in the result, which
 	// should be the address of either:
 	// - a string, in which case it will hold the response body, or
 	// - a struct with xml tags.
-	GetXpath(xpath string, nslist []Namespace, result interface{}) error
+	// GetOptions can be added to qualify the operation.
+	GetXpath(xpath string, nslist []Namespace, result interface{}, options ...GetOption) error
 
 	// GetConfigSubtree issues a GET-CONFIG request, with the supplied subtree filter and source, and stores the
 	// response in the result, which should be the address of either:
 	// - a string, in which case it will hold the response body, or
 	// - a struct with xml tags.
-	GetConfigSubtree(filter interface{}, source string, result interface{}) error
+	// The filter can be built with Container, Select and Match, or derived from the result by StructFilter.
+	// GetOptions can be added to qualify the operation.
+	GetConfigSubtree(filter interface{}, source string, result interface{}, options ...GetOption) error
 
 	// GetConfigXpath issues a GET-CONFIG request, with the supplied xpath filter, source and namespace list and stores the
 	// response in the result, which should be the address of either:
 	// - a string, in which case it will hold the response body, or
 	// - a struct with xml tags.
-	GetConfigXpath(xpath string, nslist []Namespace, source string, result interface{}) error
+	// GetOptions can be added to qualify the operation.
+	GetConfigXpath(xpath string, nslist []Namespace, source string, result interface{}, options ...GetOption) error
+
+	// GetConfigSubtreeTo issues a GET-CONFIG request, with the supplied subtree filter and source, and writes the
+	// content of the data element in the response to w as it is received, without buffering the complete response.
+	GetConfigSubtreeTo(filter interface{}, source string, w io.Writer) error
+
+	// GetConfigSubtreeFunc issues a GET-CONFIG request, with the supplied subtree filter and source, and invokes fn
+	// with each xml token of the content of the data element in the response, as it is received.
+	// If fn returns an error, the remainder of the response is discarded and the error is returned.
+	GetConfigSubtreeFunc(filter interface{}, source string, fn func(xml.Token) error) error
+
+	// GetData issues an NMDA get-data request (RFC 8526) for the specified datastore, such as OperationalCfg, and
+	// stores the response in the result, which should be the address of either:
+	// - a string, in which case it will hold the response body, or
+	// - a struct with xml tags.
+	// GetDataOptions can be added to filter the data retrieved.
+	GetData(datastore string, result interface{}, options ...GetDataOption) error
+
+	// EditData issues an NMDA edit-data request (RFC 8526) defined by config to be applied to the specified
+	// datastore, which must be RunningCfg or CandidateCfg.
+	// config is defined in the same way as for EditConfig; the DefaultOperation EditOption can be added to qualify
+	// the operation.
+	EditData(datastore string, config ConfigOption, options ...EditOption) error
+
+	// GetNetconfState returns the monitoring state of the server (RFC 6022), including its datastores and locks,
+	// schemas, sessions and statistics.
+	GetNetconfState() (*NetconfState, error)
 
 	// GetSchemas returns an array of schemas supported by the device.
 	GetSchemas() ([]Schema, error)
 
 	// GetSchema returns the text of the schema identified by id and version, in the format defined by fmt.
+	// version may be empty if the server holds a single version of the schema.
 	GetSchema(id, version, fmt string) (string, error)
 
+	// GetYangLibrary returns the YANG library of the device, from the yang-library tree (RFC 8525) if the server
+	// advertises the :yang-library:1.1 capability, or the legacy modules-state (RFC 7895) if it advertises
+	// :yang-library:1.0. If neither is advertised, modules-state is used if yang-library cannot be retrieved.
+	GetYangLibrary() (*YangLibrary, error)
+
+	// InvokeRPC issues a request for the YANG rpc with the name and namespace, and stores its output parameters in
+	// output, which should be the address of either:
+	// - a string, in which case it will hold the content of the reply, or
+	// - a struct with xml tags, into which the output parameters are decoded.
+	// output may be nil if the rpc has no output parameters, and is not changed if the reply is <ok/>.
+	// input defines the input parameters of the rpc, and may be nil, an xml string, or a struct with xml tags whose
+	// fields are marshalled as the children of the rpc element.
+	InvokeRPC(name, namespace string, input, output interface{}) error
+
+	// InvokeAction issues a request for a YANG action (RFC 7950 section 7.15), and stores its output parameters in
+	// output, which is defined as for InvokeRPC, as is input.
+	// path identifies the data node on which the action is invoked and the action itself, which is the last node at
+	// the deepest level of the path, for example:
+	//   Container("interfaces", Container("interface", Match("name", "eth0"), Select("reset"))).Namespace(ns)
+	InvokeAction(path *FilterNode, input, output interface{}) error
+
 	// EditConfig issues an edit-config request defined by config to be applied to the target configuration.
 	// EditOptions can be added to qualify the operation.
 	// config will be defined by a ConfigOption, which can be one of:
 	// - Cfg(cfg), where cfg is
 	//   o   an xml string, in which case it will be used verbatim as the content of the <config> element.
-	//   o   a struct with xml tags that will be marshalled as the child of the <config> element.
+	//   o   a struct with xml tags that will be marshalled as the child of the <config> element; Op fields can be
+	//       used to define the operation applied to individual elements.
 	// - CfgURL(url), in which case the configuration is defined by a <url> element.
+	// - CfgReader(r), in which case the content of the <config> element is copied from r as the request is sent.
 	EditConfig(target string, config ConfigOption, options ...EditOption) error
 
 	// EditConfigCfg issues an edit-config request defined by config to be applied to the target configuration.
@@ -81,41 +140,131 @@ type OpSession interface {
 	// Unlock issues an unlock request on the target configuration.
 	Unlock(target string) error
 
+	// PartialLock issues a partial-lock request (RFC 5717), locking the nodes of the running configuration selected
+	// by the xpath expressions in selects, whose prefixes are defined by nslist, and returns a PartialLockResult that
+	// holds the lock-id and the instance identifiers of the locked nodes.
+	// If the lock is denied, the session that holds it can be obtained from the error with common.LockHolder.
+	PartialLock(selects []string, nslist []Namespace) (*PartialLockResult, error)
+
+	// PartialUnlock issues a partial-unlock request, releasing the partial lock identified by lockID.
+	PartialUnlock(lockID uint32) error
+
 	// Discard issues a discard changes request.
 	Discard() error
 
+	// Commit issues a commit request, committing the candidate configuration to the running configuration.
+	// CommitOptions can be added to request a confirmed commit (:confirmed-commit:1.1), which will be rolled back
+	// unless confirmed by a subsequent commit before the timeout expires.
+	Commit(options ...CommitOption) error
+
+	// CancelCommit issues a cancel-commit request, cancelling an ongoing confirmed commit.
+	// persistID identifies a persistent confirmed commit, and should be empty if the commit was not persistent.
+	CancelCommit(persistID string) error
+
+	// Validate issues a validate request (:validate:1.1).
+	// source is defined by a CfgDsOpt, which can be one of:
+	// - DsName(name) where name defines the configuration data store name (Running, Candidate ...)
+	// - DsURL(url) where url defines the url of the datastore
+	// - DsConfig(cfg) where cfg defines inline configuration, as accepted by Cfg.
+	Validate(source CfgDsOpt) error
+
 	// CloseSession issues a close session request.
 	CloseSession() error
 
 	// KillSession issues a kill session request for the specified session id.
 	KillSession(id uint64) error
+
+	// CreateSubscription issues a create-subscription request (RFC 5277) for the specified event stream, which
+	// defaults to the NETCONF stream if empty, and returns a Subscription that delivers the events received.
+	// filter selects the events delivered, and may be nil; it can be one of:
+	// - NotificationSubtree(filter) to select events by a subtree filter.
+	// - NotificationXpath(xpath, nslist) to select events by an xpath expression.
+	// If startTime is not zero, stored events are replayed from that time; if stopTime is not zero, the subscription
+	// is completed at that time. The Subscription's notification channel is closed once the subscription is complete.
+	CreateSubscription(stream string, filter NotificationFilter, startTime, stopTime time.Time) (*Subscription, error)
+
+	// GetStreams returns the event streams supported by the device.
+	GetStreams() ([]Stream, error)
+
+	// EstablishSubscription issues an establish-subscription request (RFC 8639) and returns a DynamicSubscription
+	// that delivers the notifications of the subscription. Several subscriptions can be established on a session,
+	// but CreateSubscription should not be used on the same session.
+	// options define the subscription, which can be to either:
+	// - an event stream, defined by EventStream(name), optionally with StreamSubtree or StreamXpath filters and
+	//   a ReplayStartTime, or
+	// - a datastore (RFC 8641), defined by DatastoreSource(datastore), optionally with DatastoreSubtree or
+	//   DatastoreXpath filters, and either a Periodic or OnChange trigger.
+	EstablishSubscription(options ...SubscriptionOption) (*DynamicSubscription, error)
+
+	// ModifySubscription issues a modify-subscription request, which can change the filter, stop time or trigger
+	// period of the subscription identified by id.
+	ModifySubscription(id uint32, options ...SubscriptionOption) error
+
+	// DeleteSubscription issues a delete-subscription request for a subscription established by this session, and
+	// closes its events channel.
+	DeleteSubscription(id uint32) error
+
+	// KillSubscription issues a kill-subscription request for a subscription established by any session.
+	KillSubscription(id uint32) error
 }
 
 type sImpl struct {
 	client.Session
+	subs      subscriptionRouter
+	checkCaps bool
 }
 
 func (s *sImpl) Close() {
 	s.Session.Close()
 }
 
-func (s *sImpl) GetSubtree(filter, result interface{}) error {
-	return s.handleGetRequest(createGetSubtreeRequest(filter), result)
+func (s *sImpl) GetSubtree(filter, result interface{}, options ...GetOption) error {
+	return s.handleGetReq(createGetSubtreeRequest(filter), result, options)
 }
 
-func (s *sImpl) GetXpath(xpath string, nslist []Namespace, result interface{}) error {
-	return s.handleGetRequest(createGetXpathRequest(xpath, nslist), result)
+func (s *sImpl) GetXpath(xpath string, nslist []Namespace, result interface{}, options ...GetOption) error {
+	if err := s.requireCapability(common.CapXpath); err != nil {
+		return err
+	}
+	return s.handleGetReq(createGetXpathRequest(xpath, nslist), result, options)
 }
 
-func (s *sImpl) GetConfigSubtree(filter interface{}, source string, result interface{}) error {
-	return s.handleGetRequest(createGetConfigSubtreeRequest(filter, source), result)
+func (s *sImpl) GetConfigSubtree(filter interface{}, source string, result interface{}, options ...GetOption) error {
+	if err := s.requireDatastore(source, false); err != nil {
+		return err
+	}
+	return s.handleGetConfigReq(createGetConfigSubtreeRequest(filter, source), result, options)
 }
 
-func (s *sImpl) GetConfigXpath(xpath string, nslist []Namespace, source string, result interface{}) error {
-	return s.handleGetRequest(createGetConfigXpathRequest(xpath, source, nslist), result)
+func (s *sImpl) GetConfigXpath(xpath string, nslist []Namespace, source string, result interface{}, options ...GetOption) error {
+	if err := s.requireCapability(common.CapXpath); err != nil {
+		return err
+	}
+	if err := s.requireDatastore(source, false); err != nil {
+		return err
+	}
+	return s.handleGetConfigReq(createGetConfigXpathRequest(xpath, source, nslist), result, options)
+}
+
+func (s *sImpl) GetConfigSubtreeTo(filter interface{}, source string, w io.Writer) error {
+	tw := client.NewTokenWriter(w)
+	if err := s.GetConfigSubtreeFunc(filter, source, tw.WriteToken); err != nil {
+		return err
+	}
+	return tw.Flush()
+}
+
+func (s *sImpl) GetConfigSubtreeFunc(filter interface{}, source string, fn func(xml.Token) error) error {
+	if err := s.requireDatastore(source, false); err != nil {
+		return err
+	}
+	return s.handleStreamRequest(createGetConfigSubtreeRequest(filter, source), fn)
 }
 
 func (s *sImpl) EditConfig(target string, config ConfigOption, options ...EditOption) error {
+	if err := s.requireDatastore(target, true); err != nil {
+		return err
+	}
 	_, err := s.Session.Execute(createEditConfigRequest(target, config, options...))
 	return err
 }
@@ -135,20 +284,64 @@ func (s *sImpl) DeleteConfig(target CfgDsOpt) error {
 }
 
 func (s *sImpl) Lock(target string) error {
+	if err := s.requireDatastore(target, false); err != nil {
+		return err
+	}
 	_, err := s.Session.Execute(createLockRequest(target))
 	return err
 }
 
 func (s *sImpl) Unlock(target string) error {
+	if err := s.requireDatastore(target, false); err != nil {
+		return err
+	}
 	_, err := s.Session.Execute(createUnlockRequest(target))
 	return err
 }
 
 func (s *sImpl) Discard() error {
+	if err := s.requireCapability(common.CapCandidate); err != nil {
+		return err
+	}
 	_, err := s.Session.Execute(createDiscardRequest())
 	return err
 }
 
+func (s *sImpl) Commit(options ...CommitOption) error {
+	req := createCommitRequest(options...)
+	if err := s.requireCapability(common.CapCandidate); err != nil {
+		return err
+	}
+	switch {
+	case req.Persist != "" || req.PersistID != "":
+		if err := s.requireCapability(common.CapConfirmedCommit11); err != nil {
+			return err
+		}
+	case req.Confirmed != nil:
+		if err := s.requireCapability(common.CapConfirmedCommit10, common.CapConfirmedCommit11); err != nil {
+			return err
+		}
+	}
+	_, err := s.Session.Execute(req)
+	return err
+}
+
+func (s *sImpl) CancelCommit(persistID string) error {
+	if err := s.requireCapability(common.CapConfirmedCommit11); err != nil {
+		return err
+	}
+	_, err := s.Session.Execute(createCancelCommitRequest(persistID))
+	return err
+}
+
+func (s *sImpl) Validate(source CfgDsOpt) error {
+	if err := s.requireCapability(common.CapValidate10, common.CapValidate11); err != nil {
+		return err
+	}
+	_, err := s.Session.Execute(createValidateRequest(source))
+	return err
+}
+
 func (s *sImpl) CloseSession() error {
 	_, err := s.Session.Execute(createCloseSessionRequest())
 	return err
@@ -174,9 +367,25 @@ func (s *sImpl) GetSchema(id, version, format string) (string, error) {
 	if err != nil {
 		return "", err
 	}
-	data := &Data{}
-	err = xml.Unmarshal([]byte(rply.Data), data)
-	return data.Content, err
+	data := &schemaData{}
+	if err = xml.Unmarshal([]byte(rply.Data), data); err != nil {
+		return "", err
+	}
+	if len(data.Elements) == 0 {
+		// A text format, such as yang, whose characters may have been escaped.
+		return data.Text, nil
+	}
+	return data.Content, nil
+}
+
+// schemaData holds the content of a get-schema reply.
+type schemaData struct {
+	XMLName  xml.Name `xml:"data"`
+	Content  string   `xml:",innerxml"`
+	Text     string   `xml:",chardata"`
+	Elements []struct {
+		XMLName xml.Name
+	} `xml:",any"`
 }
 
 // Request structs.
@@ -194,20 +403,24 @@ type Config struct {
 }
 
 type GetReq struct {
-	XMLName xml.Name `xml:"get"`
-	Filter  *Filter
+	XMLName      xml.Name `xml:"get"`
+	Filter       *Filter
+	FilterBody   string `xml:",innerxml"`
+	WithDefaults *WithDefaultsParam
 }
 
 type ConfigType struct {
-	Type string `xml:",innerxml"`
-	URL  string `xml:"url,omitempty"`
+	Type   string `xml:",innerxml"`
+	URL    string `xml:"url,omitempty"`
+	Config *Config
 }
 
 type GetConfigReq struct {
-	XMLName    xml.Name    `xml:"get-config"`
-	Source     *ConfigType `xml:"source"`
-	Filter     *Filter
-	FilterBody string `xml:",innerxml"`
+	XMLName      xml.Name    `xml:"get-config"`
+	Source       *ConfigType `xml:"source"`
+	Filter       *Filter
+	FilterBody   string `xml:",innerxml"`
+	WithDefaults *WithDefaultsParam
 }
 
 type EditConfigReq struct {
@@ -218,6 +431,12 @@ type EditConfigReq struct {
 	DefaultOperation string      `xml:"default-operation,omitempty"`
 	Config           *Config
 	ConfigURL        string `xml:"url,omitempty"`
+	configReader     io.Reader
+}
+
+// ContentReader returns the reader that delivers the configuration content, if defined by CfgReader.
+func (r *EditConfigReq) ContentReader() io.Reader {
+	return r.configReader
 }
 
 type CopyConfigReq struct {
@@ -245,6 +464,24 @@ type DiscardReq struct {
 	XMLName xml.Name `xml:"discard-changes"`
 }
 
+type CommitReq struct {
+	XMLName        xml.Name  `xml:"commit"`
+	Confirmed      *struct{} `xml:"confirmed"`
+	ConfirmTimeout uint32    `xml:"confirm-timeout,omitempty"`
+	Persist        string    `xml:"persist,omitempty"`
+	PersistID      string    `xml:"persist-id,omitempty"`
+}
+
+type CancelCommitReq struct {
+	XMLName   xml.Name `xml:"cancel-commit"`
+	PersistID string   `xml:"persist-id,omitempty"`
+}
+
+type ValidateReq struct {
+	XMLName xml.Name    `xml:"validate"`
+	Source  *ConfigType `xml:"source"`
+}
+
 type CloseSessionReq struct {
 	XMLName xml.Name `xml:"close-session"`
 }
@@ -257,8 +494,8 @@ type KillSessionReq struct {
 type GetSchema struct {
 	XMLName xml.Name `xml:"urn:ietf:params:xml:ns:yang:ietf-netconf-monitoring get-schema"`
 	ID      string   `xml:"identifier"`
-	Vsn     string   `xml:"version"`
-	Fmt     string   `xml:"format"`
+	Vsn     string   `xml:"version,omitempty"`
+	Fmt     string   `xml:"format,omitempty"`
 }
 
 // ConfigOption defines the configuration to be applied by an edit config operation
@@ -270,6 +507,18 @@ func Cfg(cfg interface{}) ConfigOption {
 	}
 }
 
+// CfgReader defines the configuration as xml content to be read from r.
+// The content is copied to the transport as the request is sent, without being buffered, so it
+// is suitable for very large configurations.
+// If r fails, the request is completed in a malformed form, so that the server rejects it without applying
+// any of the content, and the read error is returned by the operation.
+func CfgReader(r io.Reader) ConfigOption {
+	return func(req *EditConfigReq) {
+		req.Config = &Config{Union: common.GetUnion(common.StreamContentMarker)}
+		req.configReader = r
+	}
+}
+
 func CfgURL(url string) ConfigOption {
 	return func(req *EditConfigReq) {
 		req.ConfigURL = url
@@ -291,6 +540,47 @@ func DsURL(url string) CfgDsOpt {
 	}
 }
 
+// DsConfig defines inline configuration, as accepted by Cfg, as the source of an operation.
+func DsConfig(cfg interface{}) CfgDsOpt {
+	return func(t *ConfigType) {
+		t.Config = &Config{Union: common.GetUnion(cfg)}
+	}
+}
+
+// CommitOption configures a commit operation.
+type CommitOption func(*CommitReq)
+
+// Confirmed requests a confirmed commit.
+func Confirmed() CommitOption {
+	return func(req *CommitReq) {
+		req.Confirmed = &struct{}{}
+	}
+}
+
+// ConfirmTimeout requests a confirmed commit that will be rolled back if not confirmed within secs seconds.
+func ConfirmTimeout(secs uint32) CommitOption {
+	return func(req *CommitReq) {
+		req.Confirmed = &struct{}{}
+		req.ConfirmTimeout = secs
+	}
+}
+
+// Persist requests a confirmed commit that persists beyond the end of the session, and which can be confirmed or
+// cancelled from any session by supplying id as the persist-id.
+func Persist(id string) CommitOption {
+	return func(req *CommitReq) {
+		req.Confirmed = &struct{}{}
+		req.Persist = id
+	}
+}
+
+// PersistID identifies the persistent confirmed commit that is being confirmed.
+func PersistID(id string) CommitOption {
+	return func(req *CommitReq) {
+		req.PersistID = id
+	}
+}
+
 // EditOption configures an edit config operation.
 type EditOption func(*EditConfigReq)
 
@@ -318,7 +608,7 @@ func (r *EditConfigReq) applyOpts(options ...EditOption) {
 	}
 }
 
-func createGetSubtreeRequest(s interface{}) common.Request {
+func createGetSubtreeRequest(s interface{}) *GetReq {
 	req := &GetReq{}
 	if s != nil {
 		req.Filter = &Filter{Type: "subtree", Union: common.GetUnion(s)}
@@ -326,8 +616,8 @@ func createGetSubtreeRequest(s interface{}) common.Request {
 	return req
 }
 
-func createGetXpathRequest(xpath string, nslist []Namespace) common.Request {
-	return fmt.Sprintf(`<get><filter %s type="xpath" select=%q/></get>`, getNamespaceAttributes(nslist), xpath)
+func createGetXpathRequest(xpath string, nslist []Namespace) *GetReq {
+	return &GetReq{FilterBody: createXpathFilter(xpath, nslist)}
 }
 
 func getNamespaceAttributes(nslist []Namespace) string {
@@ -338,7 +628,7 @@ func getNamespaceAttributes(nslist []Namespace) string {
 	return strings.TrimSpace(attrs)
 }
 
-func createGetConfigSubtreeRequest(s interface{}, source string) common.Request {
+func createGetConfigSubtreeRequest(s interface{}, source string) *GetConfigReq {
 	// xml Marshaller will not create self-closing tags (and some devices require it)...
 	req := &GetConfigReq{Source: &ConfigType{Type: "<" + source + "/>"}}
 	if s != nil {
@@ -347,7 +637,7 @@ func createGetConfigSubtreeRequest(s interface{}, source string) common.Request
 	return req
 }
 
-func createGetConfigXpathRequest(xpath, source string, nslist []Namespace) common.Request {
+func createGetConfigXpathRequest(xpath, source string, nslist []Namespace) *GetConfigReq {
 	// xml Marshaller will not create self-closing tags....
 	req := &GetConfigReq{Source: &ConfigType{Type: "<" + source + "/>"}}
 	if xpath != "" {
@@ -392,6 +682,24 @@ func createDiscardRequest() *DiscardReq {
 	return &DiscardReq{}
 }
 
+func createCommitRequest(options ...CommitOption) *CommitReq {
+	req := &CommitReq{}
+	for _, opt := range options {
+		opt(req)
+	}
+	return req
+}
+
+func createCancelCommitRequest(persistID string) *CancelCommitReq {
+	return &CancelCommitReq{PersistID: persistID}
+}
+
+func createValidateRequest(source CfgDsOpt) *ValidateReq {
+	req := &ValidateReq{Source: &ConfigType{}}
+	source(req.Source)
+	return req
+}
+
 func createKillSessionRequest(id uint64) *KillSessionReq {
 	return &KillSessionReq{ID: id}
 }
@@ -408,6 +716,20 @@ func createGetShemasRequest() common.Request {
 	return createGetSubtreeRequest("<netconf-state><schemas/></netconf-state>")
 }
 
+func (s *sImpl) handleGetReq(req *GetReq, result interface{}, options []GetOption) (err error) {
+	if req.WithDefaults, err = s.withDefaultsParam(options); err != nil {
+		return err
+	}
+	return s.handleGetRequest(req, result)
+}
+
+func (s *sImpl) handleGetConfigReq(req *GetConfigReq, result interface{}, options []GetOption) (err error) {
+	if req.WithDefaults, err = s.withDefaultsParam(options); err != nil {
+		return err
+	}
+	return s.handleGetRequest(req, result)
+}
+
 func (s *sImpl) handleGetRequest(req common.Request, result interface{}) error {
 	reply, err := s.Session.Execute(req)
 	if err != nil {
@@ -425,3 +747,47 @@ func (s *sImpl) handleGetRequest(req common.Request, result interface{}) error {
 	}
 	return err
 }
+
+// handleStreamRequest executes the request and invokes fn with each token in the content of the
+// data element of the reply.
+func (s *sImpl) handleStreamRequest(req common.Request, fn func(xml.Token) error) error {
+	rs, err := s.Session.ExecuteStream(context.Background(), req)
+	if err != nil {
+		return err
+	}
+
+	err = streamData(rs, fn)
+	if cerr := rs.Close(); err == nil || err == io.EOF {
+		err = cerr
+	}
+	return err
+}
+
+func streamData(rs *client.ReplyStream, fn func(xml.Token) error) error {
+	depth := 0
+	for {
+		token, err := rs.Token()
+		if err != nil {
+			return err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			depth++
+			if depth == 1 && t.Name.Local == "data" {
+				continue
+			}
+		case xml.EndElement:
+			depth--
+			if depth == 0 && t.Name.Local == "data" {
+				continue
+			}
+		}
+
+		if depth > 0 {
+			if err = fn(token); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -1,15 +1,23 @@
 package ops
 
 import (
+	"bytes"
+	"context"
 	"encoding/xml"
 	"errors"
+	"fmt"
+	"io"
+	"strings"
 	"testing"
+	"testing/iotest"
 
 	"github.com/damianoneill/net/v2/netconf/common"
+	"github.com/damianoneill/net/v2/netconf/testserver"
 
 	"github.com/damianoneill/net/v2/netconf/mocks"
 
 	assert "github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
 )
 
 func TestGetSubtreeToString(t *testing.T) {
@@ -252,6 +260,83 @@ func TestDiscard(t *testing.T) {
 	mcli.AssertExpectations(t)
 }
 
+func TestCommit(t *testing.T) {
+	ncs, mcli := newOpsSessionWithMockClient(t)
+	mcli.On("Execute", createCommitRequest()).Return(&common.RPCReply{}, nil)
+
+	err := ncs.Commit()
+	assert.NoError(t, err, "Not expecting call to fail")
+
+	mcli.AssertExpectations(t)
+}
+
+func TestCommitConfirmed(t *testing.T) {
+	ncs, mcli := newOpsSessionWithMockClient(t)
+	req := createCommitRequest(ConfirmTimeout(120), Persist("p1"))
+	mcli.On("Execute", req).Return(&common.RPCReply{}, nil)
+
+	err := ncs.Commit(ConfirmTimeout(120), Persist("p1"))
+	assert.NoError(t, err, "Not expecting call to fail")
+
+	mcli.AssertExpectations(t)
+
+	b, _ := xml.Marshal(req)
+	assert.Equal(t, `<commit><confirmed></confirmed><confirm-timeout>120</confirm-timeout><persist>p1</persist></commit>`,
+		string(b), "Unexpected request")
+
+	b, _ = xml.Marshal(createCommitRequest(Confirmed(), PersistID("p1")))
+	assert.Equal(t, `<commit><confirmed></confirmed><persist-id>p1</persist-id></commit>`, string(b), "Unexpected request")
+}
+
+func TestCommitFailure(t *testing.T) {
+	ncs := newOpsSessionWithTestServer(t, testserver.NewTestNetconfServer(t).WithRequestHandler(testserver.FailingRequestHandler))
+	defer ncs.Close()
+
+	err := ncs.Commit()
+	var rpcErrs *common.RPCErrors
+	assert.True(t, errors.As(err, &rpcErrs), "Expecting structured error")
+	assert.Equal(t, "oops", rpcErrs.Errors[0].Message, "Unexpected error")
+}
+
+func TestCancelCommit(t *testing.T) {
+	ncs, mcli := newOpsSessionWithMockClient(t)
+	mcli.On("Execute", createCancelCommitRequest("p1")).Return(&common.RPCReply{}, nil)
+
+	err := ncs.CancelCommit("p1")
+	assert.NoError(t, err, "Not expecting call to fail")
+
+	mcli.AssertExpectations(t)
+
+	b, _ := xml.Marshal(createCancelCommitRequest(""))
+	assert.Equal(t, `<cancel-commit></cancel-commit>`, string(b), "Unexpected request")
+}
+
+func TestValidate(t *testing.T) {
+	ncs, mcli := newOpsSessionWithMockClient(t)
+	mcli.On("Execute", createValidateRequest(DsName(CandidateCfg))).Return(&common.RPCReply{}, nil)
+
+	err := ncs.Validate(DsName(CandidateCfg))
+	assert.NoError(t, err, "Not expecting call to fail")
+
+	mcli.AssertExpectations(t)
+
+	b, _ := xml.Marshal(createValidateRequest(DsName(CandidateCfg)))
+	assert.Equal(t, `<validate><source><candidate/></source></validate>`, string(b), "Unexpected request")
+}
+
+func TestValidateConfig(t *testing.T) {
+	ncs, mcli := newOpsSessionWithMockClient(t)
+	mcli.On("Execute", createValidateRequest(DsConfig(&testConfig{}))).Return(&common.RPCReply{}, nil)
+
+	err := ncs.Validate(DsConfig(&testConfig{}))
+	assert.NoError(t, err, "Not expecting call to fail")
+
+	mcli.AssertExpectations(t)
+
+	b, _ := xml.Marshal(createValidateRequest(DsConfig(`<top/>`)))
+	assert.Equal(t, `<validate><source><config><top/></config></source></validate>`, string(b), "Unexpected request")
+}
+
 func TestCloseSession(t *testing.T) {
 	ncs, mcli := newOpsSessionWithMockClient(t)
 	mcli.On("Execute", createCloseSessionRequest()).Return(&common.RPCReply{}, nil)
@@ -334,9 +419,96 @@ func TestGetSchemaExecuteError(t *testing.T) {
 	assert.Empty(t, reply, "Reply should be empty")
 }
 
+func TestGetConfigSubtreeFunc(t *testing.T) {
+	ncs := newOpsSessionWithTestServer(t, testserver.NewTestNetconfServer(t).WithRequestHandler(testserver.SmartRequesttHandler))
+	defer ncs.Close()
+
+	var elements []string
+	err := ncs.GetConfigSubtreeFunc("<top/>", RunningCfg, func(token xml.Token) error {
+		if start, ok := token.(xml.StartElement); ok {
+			elements = append(elements, start.Name.Local)
+		}
+		return nil
+	})
+	assert.NoError(t, err, "Not expecting call to fail")
+	assert.Equal(t, []string{"top", "sub", "child1"}, elements, "Unexpected elements")
+}
+
+func TestGetConfigSubtreeFuncCallbackError(t *testing.T) {
+	ncs := newOpsSessionWithTestServer(t, testserver.NewTestNetconfServer(t).
+		WithRequestHandler(testserver.SmartRequesttHandler).
+		WithRequestHandler(testserver.SmartRequesttHandler))
+	defer ncs.Close()
+
+	err := ncs.GetConfigSubtreeFunc("<top/>", RunningCfg, func(token xml.Token) error {
+		return errors.New("stop")
+	})
+	assert.EqualError(t, err, "stop", "Expecting callback error")
+
+	// The remainder of the response should have been discarded.
+	var result string
+	err = ncs.GetSubtree("<top/>", &result)
+	assert.NoError(t, err, "Not expecting call to fail")
+	assert.Equal(t, `<top><sub attr="avalue"><child1>cvalue</child1><child2/></sub></top>`, result, "Reply should contain response data")
+}
+
+func TestGetConfigSubtreeToError(t *testing.T) {
+	ncs := newOpsSessionWithTestServer(t, testserver.NewTestNetconfServer(t).WithRequestHandler(testserver.FailingRequestHandler))
+	defer ncs.Close()
+
+	var buf bytes.Buffer
+	err := ncs.GetConfigSubtreeTo("<top/>", RunningCfg, &buf)
+	assert.Error(t, err, "Expecting call to fail")
+	assert.Empty(t, buf.String(), "Not expecting any content")
+}
+
+func TestEditConfigReader(t *testing.T) {
+	ts := testserver.NewTestNetconfServer(t).WithRequestHandler(testserver.SmartRequesttHandler)
+	ncs := newOpsSessionWithTestServer(t, ts)
+	defer ncs.Close()
+
+	err := ncs.EditConfig(CandidateCfg, CfgReader(strings.NewReader(`<top><sub attr="avalue"/></top>`)))
+	assert.NoError(t, err, "Not expecting call to fail")
+
+	sh := ts.SessionHandler(ncs.ID())
+	assert.Equal(t, "edit-config", sh.LastReq().XMLName.Local, "Expected edit-config request")
+	assert.Equal(t, `<target><candidate/></target><config><top><sub attr="avalue"/></top></config>`,
+		sh.LastReq().Body, "Unexpected request body")
+}
+
+func TestEditConfigReaderFailure(t *testing.T) {
+	ts := testserver.NewTestNetconfServer(t)
+	ncs := newOpsSessionWithTestServer(t, ts)
+	defer ncs.Close()
+
+	r := io.MultiReader(strings.NewReader(`<top/>`), iotest.ErrReader(errors.New("failed")))
+	err := ncs.EditConfig(CandidateCfg, CfgReader(r))
+	assert.EqualError(t, err, "failed", "Expecting call to fail")
+
+	// The request framing should have been preserved, but the server should have rejected the request as
+	// malformed, although the content read before the failure was complete, and then ended the session.
+	var result string
+	err = ncs.GetSubtree(`<top/>`, &result)
+	assert.Error(t, err, "Expecting call to fail on closed session")
+	sh := ts.SessionHandler(ncs.ID())
+	assert.Equal(t, 1, sh.MalformedCount(), "Expected edit-config request to be rejected")
+	assert.Equal(t, 0, sh.ReqCount(), "Not expecting any request to be processed")
+}
+
+func newOpsSessionWithTestServer(t assert.TestingT, ts *testserver.TestNCServer) OpSession {
+	sshConfig := &ssh.ClientConfig{
+		User:            testserver.TestUserName,
+		Auth:            []ssh.AuthMethod{ssh.Password(testserver.TestPassword)},
+		HostKeyCallback: ssh.InsecureIgnoreHostKey(), //nolint: gosec
+	}
+	s, err := NewSession(context.Background(), sshConfig, fmt.Sprintf("localhost:%d", ts.Port()))
+	assert.NoError(t, err, "Failed to create session")
+	return s
+}
+
 func newOpsSessionWithMockClient(_ assert.TestingT) (OpSession, *mocks.OpSession) { //nolint: gocritic
 	mockClient := &mocks.OpSession{}
-	return &sImpl{mockClient}, mockClient
+	return &sImpl{Session: mockClient}, mockClient
 }
 
 type Element struct {
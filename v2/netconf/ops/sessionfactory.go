@@ -12,18 +12,32 @@ import (
 
 // NewSession connects to the  target using the ssh configuration, and establishes
 // a netconf session with default configuration.
-func NewSession(ctx context.Context, sshcfg *ssh.ClientConfig, target string) (s OpSession, err error) {
-	return NewSessionWithConfig(ctx, sshcfg, target, client.DefaultConfig)
+// SessionOptions can be added to qualify the behaviour of the session.
+func NewSession(ctx context.Context, sshcfg *ssh.ClientConfig, target string, options ...SessionOption) (s OpSession, err error) {
+	return NewSessionWithConfig(ctx, sshcfg, target, client.DefaultConfig, options...)
 }
 
 // NewSessionWithConfig connects to the  target using the ssh configuration, and establishes
 // a netconf session with the client configuration.
-func NewSessionWithConfig(ctx context.Context, sshcfg *ssh.ClientConfig, target string, cfg *client.Config) (s OpSession, err error) {
+// SessionOptions can be added to qualify the behaviour of the session.
+func NewSessionWithConfig(ctx context.Context, sshcfg *ssh.ClientConfig, target string, cfg *client.Config,
+	options ...SessionOption) (s OpSession, err error) {
 	var cs client.Session
 	if cs, err = client.NewRPCSessionWithConfig(ctx, sshcfg, target, cfg); err != nil {
 		return
 	}
 
-	s = &sImpl{Session: cs}
+	s = NewSessionFromClient(cs, options...)
 	return
 }
+
+// NewSessionFromClient returns an operations session that issues requests using an established client session,
+// such as a client.ResilientSession or a session obtained from a client.Pool.
+// SessionOptions can be added to qualify the behaviour of the session.
+func NewSessionFromClient(cs client.Session, options ...SessionOption) OpSession {
+	si := &sImpl{Session: cs}
+	for _, opt := range options {
+		opt(si)
+	}
+	return si
+}
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/damianoneill/net/v2/netconf/client"
+	"github.com/damianoneill/net/v2/netconf/common"
 
 	"github.com/damianoneill/net/v2/netconf/testserver"
 
@@ -50,6 +51,21 @@ func TestSessionSetupSuccess(t *testing.T) {
 	assert.NotNil(t, s, "OpSession should not be nil")
 }
 
+func TestSessionFromClient(t *testing.T) {
+	ts := testserver.NewTestNetconfServer(t).WithCapabilities([]string{common.CapBase10, common.CapCandidate})
+	defer ts.Close()
+
+	rs, err := client.NewResilientSession(context.Background(), testListenerDialer(ts), client.DefaultConfig,
+		&client.ReconnectConfig{})
+	assert.NoError(t, err, "Not expecting resilient session to fail")
+	s := NewSessionFromClient(rs, CheckCapabilities())
+	defer s.Close()
+
+	assert.NoError(t, s.Lock(CandidateCfg), "Not expecting lock to fail")
+	assert.ErrorIs(t, s.Lock(StartupCfg), ErrCapabilityNotSupported, "Expecting lock to fail")
+	assert.Equal(t, 1, ts.SessionHandler(s.ID()).ReqCount(), "Unexpected requests")
+}
+
 // Simple real NE access test
 
 // func TestRealNewSession(t *testing.T) {
@@ -0,0 +1,479 @@
+package ops
+
+import (
+	"encoding/xml"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/damianoneill/net/v2/netconf/common"
+)
+
+// Defines the dynamic subscription operations described by RFC 8639 (Subscribed Notifications) and
+// RFC 8641 (YANG-Push).
+
+const (
+	// SubscribedNotificationsNS is the namespace of the subscribed notifications operations and state notifications.
+	SubscribedNotificationsNS = "urn:ietf:params:xml:ns:yang:ietf-subscribed-notifications"
+	// YangPushNS is the namespace of the datastore subscription parameters and update notifications.
+	YangPushNS = "urn:ietf:params:xml:ns:yang:ietf-yang-push"
+
+	// Notification encodings.
+	EncodeXML  = "encode-xml"
+	EncodeJSON = "encode-json"
+
+	// Subscription state notifications.
+	SubscriptionStarted    = "subscription-started"
+	SubscriptionModified   = "subscription-modified"
+	SubscriptionTerminated = "subscription-terminated"
+	SubscriptionSuspended  = "subscription-suspended"
+	SubscriptionResumed    = "subscription-resumed"
+	SubscriptionCompleted  = "subscription-completed"
+	ReplayCompleted        = "replay-completed"
+)
+
+// ErrInvalidSubscriptionReply is returned if the reply to an establish-subscription request does not identify the
+// subscription.
+var ErrInvalidSubscriptionReply = errors.New("establish-subscription reply does not contain a subscription id")
+
+// SubscriptionParams defines the parameters of a dynamic subscription, common to the establish-subscription and
+// modify-subscription operations.
+type SubscriptionParams struct {
+	NSAttrs                []xml.Attr `xml:",any,attr"`
+	StreamSubtreeFilter    *StreamSubtreeFilter
+	StreamXpathFilter      string `xml:"stream-xpath-filter,omitempty"`
+	Stream                 string `xml:"stream,omitempty"`
+	ReplayStartTime        string `xml:"replay-start-time,omitempty"`
+	Datastore              string `xml:"urn:ietf:params:xml:ns:yang:ietf-yang-push datastore,omitempty"`
+	DatastoreSubtreeFilter *DatastoreSubtreeFilter
+	DatastoreXpathFilter   string `xml:"urn:ietf:params:xml:ns:yang:ietf-yang-push datastore-xpath-filter,omitempty"`
+	StopTime               string `xml:"stop-time,omitempty"`
+	Encoding               string `xml:"encoding,omitempty"`
+	Periodic               *PeriodicTrigger
+	OnChange               *OnChangeTrigger
+}
+
+type StreamSubtreeFilter struct {
+	XMLName xml.Name `xml:"stream-subtree-filter"`
+	*common.Union
+}
+
+type DatastoreSubtreeFilter struct {
+	XMLName xml.Name `xml:"urn:ietf:params:xml:ns:yang:ietf-yang-push datastore-subtree-filter"`
+	*common.Union
+}
+
+type PeriodicTrigger struct {
+	XMLName xml.Name `xml:"urn:ietf:params:xml:ns:yang:ietf-yang-push periodic"`
+	Period  uint32   `xml:"period"`
+}
+
+type OnChangeTrigger struct {
+	XMLName         xml.Name `xml:"urn:ietf:params:xml:ns:yang:ietf-yang-push on-change"`
+	DampeningPeriod *uint32  `xml:"dampening-period"`
+	SyncOnStart     *bool    `xml:"sync-on-start"`
+}
+
+type EstablishSubscriptionReq struct {
+	XMLName xml.Name `xml:"urn:ietf:params:xml:ns:yang:ietf-subscribed-notifications establish-subscription"`
+	SubscriptionParams
+}
+
+type ModifySubscriptionReq struct {
+	XMLName xml.Name `xml:"urn:ietf:params:xml:ns:yang:ietf-subscribed-notifications modify-subscription"`
+	ID      uint32   `xml:"id"`
+	SubscriptionParams
+}
+
+type DeleteSubscriptionReq struct {
+	XMLName xml.Name `xml:"urn:ietf:params:xml:ns:yang:ietf-subscribed-notifications delete-subscription"`
+	ID      uint32   `xml:"id"`
+}
+
+type KillSubscriptionReq struct {
+	XMLName xml.Name `xml:"urn:ietf:params:xml:ns:yang:ietf-subscribed-notifications kill-subscription"`
+	ID      uint32   `xml:"id"`
+}
+
+type establishSubscriptionReply struct {
+	ID *uint32 `xml:"id"`
+}
+
+// SubscriptionOption defines a parameter of a dynamic subscription.
+type SubscriptionOption func(*SubscriptionParams)
+
+// EventStream requests a subscription to the named event stream.
+func EventStream(name string) SubscriptionOption {
+	return func(p *SubscriptionParams) {
+		p.Stream = name
+	}
+}
+
+// StreamSubtree selects the events delivered by an event stream subscription by a subtree filter, which can be an
+// xml string or a struct with xml tags.
+func StreamSubtree(filter interface{}) SubscriptionOption {
+	return func(p *SubscriptionParams) {
+		p.StreamSubtreeFilter = &StreamSubtreeFilter{Union: common.GetUnion(filter)}
+	}
+}
+
+// StreamXpath selects the events delivered by an event stream subscription by an xpath expression, using the
+// prefixes defined by nslist.
+func StreamXpath(xpath string, nslist []Namespace) SubscriptionOption {
+	return func(p *SubscriptionParams) {
+		p.StreamXpathFilter = xpath
+		p.addNamespaces(nslist)
+	}
+}
+
+// ReplayStartTime requests that stored events are replayed from the specified time.
+func ReplayStartTime(t time.Time) SubscriptionOption {
+	return func(p *SubscriptionParams) {
+		p.ReplayStartTime = t.Format(time.RFC3339Nano)
+	}
+}
+
+// DatastoreSource requests a YANG-Push subscription to the specified datastore, such as OperationalCfg.
+func DatastoreSource(datastore string) SubscriptionOption {
+	return func(p *SubscriptionParams) {
+		p.Datastore = datastoreIdentity(datastore)
+		p.addNamespaces([]Namespace{{ID: "ds", Path: DatastoresNS}})
+	}
+}
+
+// DatastoreSubtree selects the data pushed by a datastore subscription by a subtree filter, which can be an xml
+// string or a struct with xml tags.
+func DatastoreSubtree(filter interface{}) SubscriptionOption {
+	return func(p *SubscriptionParams) {
+		p.DatastoreSubtreeFilter = &DatastoreSubtreeFilter{Union: common.GetUnion(filter)}
+	}
+}
+
+// DatastoreXpath selects the data pushed by a datastore subscription by an xpath expression, using the prefixes
+// defined by nslist.
+func DatastoreXpath(xpath string, nslist []Namespace) SubscriptionOption {
+	return func(p *SubscriptionParams) {
+		p.DatastoreXpathFilter = xpath
+		p.addNamespaces(nslist)
+	}
+}
+
+// StopTime requests that the subscription is completed at the specified time.
+func StopTime(t time.Time) SubscriptionOption {
+	return func(p *SubscriptionParams) {
+		p.StopTime = t.Format(time.RFC3339Nano)
+	}
+}
+
+// Encoding requests the encoding of the notifications, such as EncodeXML.
+func Encoding(encoding string) SubscriptionOption {
+	return func(p *SubscriptionParams) {
+		p.Encoding = encoding
+	}
+}
+
+// Periodic requests that a datastore subscription pushes the selected data at the specified period, which is
+// rounded down to centiseconds.
+func Periodic(period time.Duration) SubscriptionOption {
+	return func(p *SubscriptionParams) {
+		p.Periodic = &PeriodicTrigger{Period: centiseconds(period)}
+	}
+}
+
+// OnChange requests that a datastore subscription pushes changes to the selected data, with at least the
+// specified dampening period between updates.
+func OnChange(dampening time.Duration) SubscriptionOption {
+	return func(p *SubscriptionParams) {
+		period := centiseconds(dampening)
+		p.onChange().DampeningPeriod = &period
+	}
+}
+
+// SyncOnStart defines whether an on-change subscription starts by pushing the complete selected data.
+func SyncOnStart(sync bool) SubscriptionOption {
+	return func(p *SubscriptionParams) {
+		p.onChange().SyncOnStart = &sync
+	}
+}
+
+func (p *SubscriptionParams) onChange() *OnChangeTrigger {
+	if p.OnChange == nil {
+		p.OnChange = &OnChangeTrigger{}
+	}
+	return p.OnChange
+}
+
+func (p *SubscriptionParams) addNamespaces(nslist []Namespace) {
+	for _, ns := range nslist {
+		p.NSAttrs = append(p.NSAttrs, xml.Attr{Name: xml.Name{Local: "xmlns:" + ns.ID}, Value: ns.Path})
+	}
+}
+
+// Notification types delivered by dynamic subscriptions.
+
+// Anydata holds the unparsed content of an element.
+type Anydata struct {
+	Content string `xml:",innerxml"`
+}
+
+// PushUpdate is the notification that delivers the data selected by a periodic subscription, or the initial data
+// of an on-change subscription.
+type PushUpdate struct {
+	XMLName    xml.Name  `xml:"urn:ietf:params:xml:ns:yang:ietf-yang-push push-update"`
+	ID         uint32    `xml:"id"`
+	Contents   Anydata   `xml:"datastore-contents"`
+	Incomplete *struct{} `xml:"incomplete-update"`
+}
+
+// PushChangeUpdate is the notification that delivers changes to the data selected by an on-change subscription,
+// as a YANG patch.
+type PushChangeUpdate struct {
+	XMLName    xml.Name  `xml:"urn:ietf:params:xml:ns:yang:ietf-yang-push push-change-update"`
+	ID         uint32    `xml:"id"`
+	Changes    Anydata   `xml:"datastore-changes"`
+	Incomplete *struct{} `xml:"incomplete-update"`
+}
+
+// SubscriptionState is a notification that reports a change to the state of a subscription, such as
+// SubscriptionTerminated.
+type SubscriptionState struct {
+	XMLName xml.Name
+	ID      uint32 `xml:"id"`
+	// The reason identity, for terminated and suspended notifications.
+	Reason string `xml:"reason"`
+}
+
+// Type returns the type of the state notification, such as SubscriptionStarted.
+func (s *SubscriptionState) Type() string {
+	return s.XMLName.Local
+}
+
+// SubscriptionEvent is a notification delivered by a dynamic subscription. The fields corresponding to the type of
+// notification are decoded; notifications delivered by an event stream subscription are not.
+type SubscriptionEvent struct {
+	*common.Notification
+	Update       *PushUpdate
+	ChangeUpdate *PushChangeUpdate
+	State        *SubscriptionState
+}
+
+// subscriptionID returns the id of the subscription that the event relates to, if it is known.
+func (e *SubscriptionEvent) subscriptionID() (uint32, bool) {
+	switch {
+	case e.Update != nil:
+		return e.Update.ID, true
+	case e.ChangeUpdate != nil:
+		return e.ChangeUpdate.ID, true
+	case e.State != nil:
+		return e.State.ID, true
+	default:
+		return 0, false
+	}
+}
+
+// final reports whether the event ends the subscription.
+func (e *SubscriptionEvent) final() bool {
+	return e.State != nil && (e.State.Type() == SubscriptionTerminated || e.State.Type() == SubscriptionCompleted)
+}
+
+func newSubscriptionEvent(n *common.Notification) *SubscriptionEvent {
+	ev := &SubscriptionEvent{Notification: n}
+	var target interface{}
+	switch n.XMLName.Space {
+	case YangPushNS:
+		switch n.XMLName.Local {
+		case "push-update":
+			ev.Update = &PushUpdate{}
+			target = ev.Update
+		case "push-change-update":
+			ev.ChangeUpdate = &PushChangeUpdate{}
+			target = ev.ChangeUpdate
+		}
+	case SubscribedNotificationsNS:
+		switch n.XMLName.Local {
+		case SubscriptionStarted, SubscriptionModified, SubscriptionTerminated, SubscriptionSuspended,
+			SubscriptionResumed, SubscriptionCompleted, ReplayCompleted:
+			ev.State = &SubscriptionState{}
+			target = ev.State
+		}
+	}
+	if target != nil && n.Decode(target) != nil {
+		// Deliver the notification undecoded.
+		ev.Update, ev.ChangeUpdate, ev.State = nil, nil, nil
+	}
+	return ev
+}
+
+// DynamicSubscription delivers the notifications of a subscription established by EstablishSubscription.
+type DynamicSubscription struct {
+	// The id allocated to the subscription by the server.
+	ID      uint32
+	stream  bool
+	events  chan *SubscriptionEvent
+	dropped uint64
+}
+
+// Events returns the channel that delivers the notifications of the subscription. The channel is closed when the
+// subscription is terminated, completed or deleted, or the session is closed.
+func (d *DynamicSubscription) Events() <-chan *SubscriptionEvent {
+	return d.events
+}
+
+// Dropped returns the number of notifications that have been dropped because the events channel was full.
+func (d *DynamicSubscription) Dropped() uint64 {
+	return atomic.LoadUint64(&d.dropped)
+}
+
+func (d *DynamicSubscription) deliver(ev *SubscriptionEvent) {
+	select {
+	case d.events <- ev:
+	default:
+		atomic.AddUint64(&d.dropped, 1)
+	}
+}
+
+// subscriptionRouter routes the notifications received by a session to its dynamic subscriptions.
+type subscriptionRouter struct {
+	mu    sync.Mutex
+	nchan chan *common.Notification
+	subs  map[uint32]*DynamicSubscription
+}
+
+func (s *sImpl) EstablishSubscription(options ...SubscriptionOption) (*DynamicSubscription, error) {
+	req := createEstablishSubscriptionRequest(options...)
+
+	// The router is locked until the subscription is registered, so that notifications that arrive before the
+	// reply is processed are not lost.
+	r := &s.subs
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var reply *common.RPCReply
+	var err error
+	if r.nchan == nil {
+		// The session only sends notifications to the channel if the request succeeds.
+		nchan := make(chan *common.Notification, subscriptionBufferSize)
+		if reply, err = s.Session.Subscribe(req, nchan); err == nil {
+			r.nchan = nchan
+			r.subs = make(map[uint32]*DynamicSubscription)
+			go r.dispatch(nchan)
+		}
+	} else {
+		reply, err = s.Session.Execute(req)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result := &establishSubscriptionReply{}
+	if err = xml.Unmarshal([]byte("<reply>"+reply.Data+"</reply>"), result); err != nil {
+		return nil, err
+	}
+	if result.ID == nil {
+		return nil, ErrInvalidSubscriptionReply
+	}
+
+	sub := &DynamicSubscription{
+		ID:     *result.ID,
+		stream: req.Stream != "",
+		events: make(chan *SubscriptionEvent, subscriptionBufferSize),
+	}
+	r.subs[sub.ID] = sub
+	return sub, nil
+}
+
+func (s *sImpl) ModifySubscription(id uint32, options ...SubscriptionOption) error {
+	_, err := s.Session.Execute(createModifySubscriptionRequest(id, options...))
+	return err
+}
+
+func (s *sImpl) DeleteSubscription(id uint32) error {
+	if _, err := s.Session.Execute(createDeleteSubscriptionRequest(id)); err != nil {
+		return err
+	}
+	s.subs.remove(id)
+	return nil
+}
+
+func (s *sImpl) KillSubscription(id uint32) error {
+	_, err := s.Session.Execute(createKillSubscriptionRequest(id))
+	return err
+}
+
+// dispatch routes notifications until the session is closed, when all subscriptions are closed.
+func (r *subscriptionRouter) dispatch(nchan chan *common.Notification) {
+	for n := range nchan {
+		r.route(newSubscriptionEvent(n))
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for id, sub := range r.subs {
+		delete(r.subs, id)
+		close(sub.events)
+	}
+	r.nchan = nil
+}
+
+// route delivers the event to the subscription it relates to. Notifications from event streams do not identify
+// the subscription, so are delivered to every event stream subscription.
+func (r *subscriptionRouter) route(ev *SubscriptionEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	id, ok := ev.subscriptionID()
+	if !ok {
+		for _, sub := range r.subs {
+			if sub.stream {
+				sub.deliver(ev)
+			}
+		}
+		return
+	}
+
+	if sub, ok := r.subs[id]; ok {
+		sub.deliver(ev)
+		if ev.final() {
+			delete(r.subs, id)
+			close(sub.events)
+		}
+	}
+}
+
+func (r *subscriptionRouter) remove(id uint32) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if sub, ok := r.subs[id]; ok {
+		delete(r.subs, id)
+		close(sub.events)
+	}
+}
+
+func createEstablishSubscriptionRequest(options ...SubscriptionOption) *EstablishSubscriptionReq {
+	req := &EstablishSubscriptionReq{}
+	for _, opt := range options {
+		opt(&req.SubscriptionParams)
+	}
+	return req
+}
+
+func createModifySubscriptionRequest(id uint32, options ...SubscriptionOption) *ModifySubscriptionReq {
+	req := &ModifySubscriptionReq{ID: id}
+	for _, opt := range options {
+		opt(&req.SubscriptionParams)
+	}
+	return req
+}
+
+func createDeleteSubscriptionRequest(id uint32) *DeleteSubscriptionReq {
+	return &DeleteSubscriptionReq{ID: id}
+}
+
+func createKillSubscriptionRequest(id uint32) *KillSubscriptionReq {
+	return &KillSubscriptionReq{ID: id}
+}
+
+func centiseconds(d time.Duration) uint32 {
+	return uint32(d / (10 * time.Millisecond))
+}
@@ -0,0 +1,150 @@
+package ops
+
+import (
+	"encoding/xml"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/damianoneill/net/v2/netconf/common"
+	"github.com/damianoneill/net/v2/netconf/testserver"
+	"github.com/stretchr/testify/mock"
+
+	assert "github.com/stretchr/testify/require"
+)
+
+func TestEstablishSubscriptionRequest(t *testing.T) {
+	b, _ := xml.Marshal(createEstablishSubscriptionRequest(DatastoreSource(OperationalCfg),
+		DatastoreXpath("/if:interfaces", []Namespace{{ID: "if", Path: "urn:if"}}),
+		StopTime(time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)), Encoding(EncodeXML),
+		OnChange(500*time.Millisecond), SyncOnStart(false)))
+	assert.Equal(t, `<establish-subscription xmlns="urn:ietf:params:xml:ns:yang:ietf-subscribed-notifications" `+
+		`xmlns:ds="urn:ietf:params:xml:ns:yang:ietf-datastores" xmlns:if="urn:if">`+
+		`<datastore xmlns="urn:ietf:params:xml:ns:yang:ietf-yang-push">ds:operational</datastore>`+
+		`<datastore-xpath-filter xmlns="urn:ietf:params:xml:ns:yang:ietf-yang-push">/if:interfaces</datastore-xpath-filter>`+
+		`<stop-time>2020-01-02T03:04:05Z</stop-time><encoding>encode-xml</encoding>`+
+		`<on-change xmlns="urn:ietf:params:xml:ns:yang:ietf-yang-push">`+
+		`<dampening-period>50</dampening-period><sync-on-start>false</sync-on-start></on-change>`+
+		`</establish-subscription>`, string(b), "Unexpected request")
+
+	b, _ = xml.Marshal(createEstablishSubscriptionRequest(EventStream("NETCONF"), StreamSubtree(`<event xmlns="urn:test"/>`),
+		ReplayStartTime(time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC))))
+	assert.Equal(t, `<establish-subscription xmlns="urn:ietf:params:xml:ns:yang:ietf-subscribed-notifications">`+
+		`<stream-subtree-filter><event xmlns="urn:test"/></stream-subtree-filter><stream>NETCONF</stream>`+
+		`<replay-start-time>2020-01-02T03:04:05Z</replay-start-time></establish-subscription>`, string(b), "Unexpected request")
+}
+
+func TestModifySubscriptionRequest(t *testing.T) {
+	b, _ := xml.Marshal(createModifySubscriptionRequest(22, DatastoreSubtree(`<interfaces xmlns="urn:if"/>`),
+		Periodic(5*time.Second)))
+	assert.Equal(t, `<modify-subscription xmlns="urn:ietf:params:xml:ns:yang:ietf-subscribed-notifications"><id>22</id>`+
+		`<datastore-subtree-filter xmlns="urn:ietf:params:xml:ns:yang:ietf-yang-push"><interfaces xmlns="urn:if"/></datastore-subtree-filter>`+
+		`<periodic xmlns="urn:ietf:params:xml:ns:yang:ietf-yang-push"><period>500</period></periodic>`+
+		`</modify-subscription>`, string(b), "Unexpected request")
+}
+
+func TestEstablishSubscription(t *testing.T) {
+	ncs, mcli := newOpsSessionWithMockClient(t)
+
+	var nchan chan *common.Notification
+	mcli.On("Subscribe", createEstablishSubscriptionRequest(DatastoreSource(OperationalCfg), Periodic(time.Second)), mock.Anything).
+		Run(func(args mock.Arguments) {
+			nchan = args.Get(1).(chan *common.Notification)
+		}).Return(subscriptionIDReply(22), nil)
+	mcli.On("Execute", createEstablishSubscriptionRequest(DatastoreSource(OperationalCfg), OnChange(0))).
+		Return(subscriptionIDReply(23), nil)
+
+	periodic, err := ncs.EstablishSubscription(DatastoreSource(OperationalCfg), Periodic(time.Second))
+	assert.NoError(t, err, "Not expecting subscription to fail")
+	assert.Equal(t, uint32(22), periodic.ID, "Unexpected subscription id")
+
+	onChange, err := ncs.EstablishSubscription(DatastoreSource(OperationalCfg), OnChange(0))
+	assert.NoError(t, err, "Not expecting subscription to fail")
+	assert.Equal(t, uint32(23), onChange.ID, "Unexpected subscription id")
+
+	nchan <- subscriptionNotification(SubscribedNotificationsNS, SubscriptionStarted, `<id>22</id>`)
+	nchan <- subscriptionNotification(YangPushNS, "push-change-update",
+		`<id>23</id><datastore-changes><yang-patch/></datastore-changes>`)
+	nchan <- subscriptionNotification(YangPushNS, "push-update",
+		`<id>22</id><datastore-contents><interfaces/></datastore-contents><incomplete-update/>`)
+	nchan <- subscriptionNotification(YangPushNS, "push-update", `<id>99</id>`)
+	nchan <- subscriptionNotification(SubscribedNotificationsNS, SubscriptionTerminated,
+		`<id>22</id><reason xmlns:sn="urn:ietf:params:xml:ns:yang:ietf-subscribed-notifications">sn:no-such-subscription</reason>`)
+
+	ev := <-periodic.Events()
+	assert.Equal(t, SubscriptionStarted, ev.State.Type(), "Expected subscription started")
+	ev = <-periodic.Events()
+	assert.Equal(t, `<interfaces/>`, ev.Update.Contents.Content, "Unexpected update content")
+	assert.NotNil(t, ev.Update.Incomplete, "Expected incomplete update")
+	ev = <-periodic.Events()
+	assert.Equal(t, SubscriptionTerminated, ev.State.Type(), "Expected subscription terminated")
+	assert.Equal(t, "sn:no-such-subscription", ev.State.Reason, "Unexpected reason")
+	_, ok := <-periodic.Events()
+	assert.False(t, ok, "Expected events to be closed")
+
+	ev = <-onChange.Events()
+	assert.Equal(t, uint32(23), ev.ChangeUpdate.ID, "Unexpected change update")
+	assert.Equal(t, `<yang-patch/>`, ev.ChangeUpdate.Changes.Content, "Unexpected change update content")
+
+	close(nchan)
+	_, ok = <-onChange.Events()
+	assert.False(t, ok, "Expected events to be closed with session")
+}
+
+func TestEstablishStreamSubscription(t *testing.T) {
+	ncs, mcli := newOpsSessionWithMockClient(t)
+
+	var nchan chan *common.Notification
+	mcli.On("Subscribe", createEstablishSubscriptionRequest(EventStream("NETCONF")), mock.Anything).
+		Run(func(args mock.Arguments) {
+			nchan = args.Get(1).(chan *common.Notification)
+		}).Return(subscriptionIDReply(7), nil)
+	mcli.On("Execute", createDeleteSubscriptionRequest(7)).Return(&common.RPCReply{}, nil)
+
+	sub, err := ncs.EstablishSubscription(EventStream("NETCONF"))
+	assert.NoError(t, err, "Not expecting subscription to fail")
+
+	nchan <- subscriptionNotification("urn:test", "event", `<id>1</id>`)
+	ev := <-sub.Events()
+	assert.Equal(t, "event", ev.XMLName.Local, "Unexpected event")
+	assert.Nil(t, ev.State, "Not expecting event to be decoded")
+
+	assert.NoError(t, ncs.DeleteSubscription(7), "Not expecting delete to fail")
+	_, ok := <-sub.Events()
+	assert.False(t, ok, "Expected events to be closed")
+}
+
+func TestEstablishSubscriptionInvalidReply(t *testing.T) {
+	ts := testserver.NewTestNetconfServer(t)
+	ncs := newOpsSessionWithTestServer(t, ts)
+	defer ncs.Close()
+
+	sub, err := ncs.EstablishSubscription(EventStream("NETCONF"))
+	assert.ErrorIs(t, err, ErrInvalidSubscriptionReply, "Expecting subscription to fail")
+	assert.Nil(t, sub, "Not expecting subscription")
+}
+
+func TestModifyKillSubscription(t *testing.T) {
+	ts := testserver.NewTestNetconfServer(t)
+	ncs := newOpsSessionWithTestServer(t, ts)
+	defer ncs.Close()
+	sh := ts.SessionHandler(ncs.ID())
+
+	assert.NoError(t, ncs.ModifySubscription(22, StopTime(time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC))))
+	assert.Equal(t, `<id>22</id><stop-time>2020-01-02T03:04:05Z</stop-time>`, sh.LastReq().Body, "Unexpected request")
+
+	assert.NoError(t, ncs.KillSubscription(22))
+	assert.Equal(t, "kill-subscription", sh.LastReq().XMLName.Local, "Unexpected request")
+	assert.Equal(t, `<id>22</id>`, sh.LastReq().Body, "Unexpected request")
+}
+
+func subscriptionIDReply(id uint32) *common.RPCReply {
+	return &common.RPCReply{Data: fmt.Sprintf(`<id xmlns=%q>%d</id>`, SubscribedNotificationsNS, id)}
+}
+
+func subscriptionNotification(space, local, body string) *common.Notification {
+	return &common.Notification{
+		XMLName: xml.Name{Space: space, Local: local},
+		Event:   fmt.Sprintf(`<%s xmlns=%q>%s</%s>`, local, space, body, local),
+	}
+}
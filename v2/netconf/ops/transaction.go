@@ -0,0 +1,240 @@
+package ops
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/damianoneill/net/v2/netconf/common"
+)
+
+// TransactionStep identifies a step of a Transaction.
+type TransactionStep string
+
+// Define the steps of a transaction.
+const (
+	StepCapabilities TransactionStep = "capabilities"
+	StepLock         TransactionStep = "lock"
+	StepEditConfig   TransactionStep = "edit-config"
+	StepValidate     TransactionStep = "validate"
+	StepCommit       TransactionStep = "commit"
+	StepHealthCheck  TransactionStep = "health-check"
+	StepCancelCommit TransactionStep = "cancel-commit"
+	StepConfirm      TransactionStep = "confirm"
+	StepDiscard      TransactionStep = "discard"
+	StepUnlock       TransactionStep = "unlock"
+)
+
+// ErrNoWritableDatastore is reported when the server supports neither the candidate datastore nor a writable
+// running datastore.
+var ErrNoWritableDatastore = errors.New("server supports neither candidate nor writable-running")
+
+// ErrConfirmedCommitNotSupported is reported when a confirmed commit is requested, but the server does not
+// support the candidate datastore and confirmed commits.
+var ErrConfirmedCommitNotSupported = errors.New("server does not support confirmed commit")
+
+// TransactionError reports the failure of a step of a Transaction.
+type TransactionError struct {
+	// The step that failed.
+	Step TransactionStep
+	// The datastore targeted by the step, if any.
+	Datastore string
+	// The cause of the failure.
+	Err error
+	// Failures of the steps taken to clean up after the failure, such as discarding changes and unlocking.
+	Cleanup []*TransactionError
+}
+
+func (e *TransactionError) Error() string {
+	msg := fmt.Sprintf("transaction %s", e.Step)
+	if e.Datastore != "" {
+		msg += " " + e.Datastore
+	}
+	msg += " failed: " + e.Err.Error()
+	for _, c := range e.Cleanup {
+		msg += "; " + c.Error()
+	}
+	return msg
+}
+
+func (e *TransactionError) Unwrap() error {
+	return e.Err
+}
+
+// HealthCheckFunc verifies that the configuration applied by a confirmed commit is acceptable, before the commit is
+// confirmed.
+type HealthCheckFunc func(s OpSession) error
+
+// TransactionOption configures a Transaction.
+type TransactionOption func(*Transaction)
+
+// WithConfirmedCommit requests that changes are committed by a confirmed commit, with the specified timeout in
+// seconds. Once committed, check is invoked; if it succeeds the commit is confirmed, otherwise it is cancelled.
+// A server that supports only :confirmed-commit:1.0 has no cancel-commit operation, so the commit is not cancelled;
+// instead it is rolled back by the server when the timeout expires, or the session is closed.
+func WithConfirmedCommit(timeoutSecs uint32, check HealthCheckFunc) TransactionOption {
+	return func(t *Transaction) {
+		t.confirmTimeout = timeoutSecs
+		t.healthCheck = check
+	}
+}
+
+// Transaction applies a set of configuration changes to a server using the safe-change workflow:
+// - the running, and candidate, datastores are locked,
+// - the changes are applied to the candidate by edit-config,
+// - the candidate is validated, if the server supports :validate,
+// - the changes are committed, optionally by a confirmed commit followed by a health check and a confirming commit,
+// - the datastores are unlocked.
+// If any step fails, the changes are discarded. The datastores are always unlocked.
+//
+// If the server does not support the candidate datastore, the changes are applied directly to a writable running
+// datastore, with the rollback-on-error error option if the server supports it. Note that each edit is then applied,
+// and rolled back, independently.
+type Transaction struct {
+	s              OpSession
+	edits          []transactionEdit
+	confirmTimeout uint32
+	healthCheck    HealthCheckFunc
+}
+
+type transactionEdit struct {
+	config  ConfigOption
+	options []EditOption
+}
+
+// NewTransaction creates a transaction that will apply changes using the session.
+func NewTransaction(s OpSession, options ...TransactionOption) *Transaction {
+	t := &Transaction{s: s}
+	for _, opt := range options {
+		opt(t)
+	}
+	return t
+}
+
+// EditConfig adds an edit-config operation to the transaction; the target datastore is determined when the
+// transaction is run.
+func (t *Transaction) EditConfig(config ConfigOption, options ...EditOption) *Transaction {
+	t.edits = append(t.edits, transactionEdit{config: config, options: options})
+	return t
+}
+
+// Run executes the transaction, returning a *TransactionError if any step fails.
+func (t *Transaction) Run() error {
+	caps := common.ParseCapabilities(t.s.ServerCapabilities())
+	switch {
+	case caps.HasCapability(common.CapCandidate):
+		if t.healthCheck != nil && !caps.HasCapability(common.CapConfirmedCommit10, common.CapConfirmedCommit11) {
+			return &TransactionError{Step: StepCapabilities, Err: ErrConfirmedCommitNotSupported}
+		}
+		return t.runCandidate(caps.HasCapability(common.CapValidate10, common.CapValidate11),
+			caps.HasCapability(common.CapConfirmedCommit11))
+	case caps.HasCapability(common.CapWritableRunning):
+		if t.healthCheck != nil {
+			return &TransactionError{Step: StepCapabilities, Err: ErrConfirmedCommitNotSupported}
+		}
+		return t.runRunning(caps.HasCapability(common.CapRollbackOnError))
+	default:
+		return &TransactionError{Step: StepCapabilities, Err: ErrNoWritableDatastore}
+	}
+}
+
+// runCandidate applies the changes to the candidate datastore, validating them if validate is true; cancel is true
+// if a failed confirmed commit can be cancelled.
+func (t *Transaction) runCandidate(validate, cancel bool) (err error) {
+	var locked []string
+	defer func() {
+		err = t.unlock(locked, err)
+	}()
+
+	for _, ds := range []string{RunningCfg, CandidateCfg} {
+		if lerr := t.s.Lock(ds); lerr != nil {
+			return &TransactionError{Step: StepLock, Datastore: ds, Err: lerr}
+		}
+		locked = append(locked, ds)
+	}
+
+	if err = t.applyCandidate(validate, cancel); err != nil {
+		if derr := t.s.Discard(); derr != nil {
+			terr := err.(*TransactionError)
+			terr.Cleanup = append(terr.Cleanup, &TransactionError{Step: StepDiscard, Datastore: CandidateCfg, Err: derr})
+		}
+	}
+	return err
+}
+
+func (t *Transaction) applyCandidate(validate, cancel bool) error {
+	for _, e := range t.edits {
+		if err := t.s.EditConfig(CandidateCfg, e.config, e.options...); err != nil {
+			return &TransactionError{Step: StepEditConfig, Datastore: CandidateCfg, Err: err}
+		}
+	}
+
+	if validate {
+		if err := t.s.Validate(DsName(CandidateCfg)); err != nil {
+			return &TransactionError{Step: StepValidate, Datastore: CandidateCfg, Err: err}
+		}
+	}
+
+	if t.healthCheck == nil {
+		if err := t.s.Commit(); err != nil {
+			return &TransactionError{Step: StepCommit, Err: err}
+		}
+		return nil
+	}
+
+	if err := t.s.Commit(ConfirmTimeout(t.confirmTimeout)); err != nil {
+		return &TransactionError{Step: StepCommit, Err: err}
+	}
+	if err := t.healthCheck(t.s); err != nil {
+		terr := &TransactionError{Step: StepHealthCheck, Err: err}
+		if !cancel {
+			// The commit is rolled back when the confirm timeout expires.
+			return terr
+		}
+		if cerr := t.s.CancelCommit(""); cerr != nil {
+			terr.Cleanup = append(terr.Cleanup, &TransactionError{Step: StepCancelCommit, Err: cerr})
+		}
+		return terr
+	}
+	if err := t.s.Commit(); err != nil {
+		return &TransactionError{Step: StepConfirm, Err: err}
+	}
+	return nil
+}
+
+func (t *Transaction) runRunning(rollback bool) (err error) {
+	if err = t.s.Lock(RunningCfg); err != nil {
+		return &TransactionError{Step: StepLock, Datastore: RunningCfg, Err: err}
+	}
+	defer func() {
+		err = t.unlock([]string{RunningCfg}, err)
+	}()
+
+	for _, e := range t.edits {
+		options := e.options
+		if rollback {
+			options = append(options[:len(options):len(options)], ErrorOption(RollbackOnErrorErrOpt))
+		}
+		if eerr := t.s.EditConfig(RunningCfg, e.config, options...); eerr != nil {
+			return &TransactionError{Step: StepEditConfig, Datastore: RunningCfg, Err: eerr}
+		}
+	}
+	return nil
+}
+
+// unlock unlocks the datastores, in reverse order, combining any failures with err.
+func (t *Transaction) unlock(locked []string, err error) error {
+	for i := len(locked) - 1; i >= 0; i-- {
+		uerr := t.s.Unlock(locked[i])
+		if uerr == nil {
+			continue
+		}
+		uterr := &TransactionError{Step: StepUnlock, Datastore: locked[i], Err: uerr}
+		if err == nil {
+			err = uterr
+		} else {
+			terr := err.(*TransactionError)
+			terr.Cleanup = append(terr.Cleanup, uterr)
+		}
+	}
+	return err
+}
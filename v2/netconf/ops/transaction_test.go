@@ -0,0 +1,188 @@
+package ops
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/damianoneill/net/v2/netconf/common"
+	"github.com/damianoneill/net/v2/netconf/testserver"
+
+	assert "github.com/stretchr/testify/require"
+)
+
+var (
+	candidateCaps = []string{common.CapBase10, common.CapCandidate, common.CapValidate11}
+	confirmedCaps = []string{common.CapBase10, common.CapCandidate, common.CapConfirmedCommit11 + "?x=y"}
+	runningCaps   = []string{common.CapBase10, common.CapWritableRunning, common.CapRollbackOnError}
+)
+
+func TestTransaction(t *testing.T) {
+	ts := testserver.NewTestNetconfServer(t).WithCapabilities(candidateCaps)
+	ncs := newOpsSessionWithTestServer(t, ts)
+	defer ncs.Close()
+
+	err := NewTransaction(ncs).EditConfig(Cfg(`<top/>`)).EditConfig(Cfg(`<other/>`), DefaultOperation(MergeOp)).Run()
+	assert.NoError(t, err, "Not expecting transaction to fail")
+
+	sh := ts.SessionHandler(ncs.ID())
+	assert.Equal(t, []string{"lock", "lock", "edit-config", "edit-config", "validate", "commit", "unlock", "unlock"},
+		requestNames(sh), "Unexpected requests")
+	assert.Equal(t, `<target><running/></target>`, sh.Reqs[0].Body, "Expected running to be locked first")
+	assert.Equal(t, `<target><running/></target>`, sh.Reqs[7].Body, "Expected running to be unlocked last")
+}
+
+func TestTransactionConfirmedCommit(t *testing.T) {
+	ts := testserver.NewTestNetconfServer(t).WithCapabilities(confirmedCaps)
+	ncs := newOpsSessionWithTestServer(t, ts)
+	defer ncs.Close()
+
+	checked := false
+	err := NewTransaction(ncs, WithConfirmedCommit(60, func(s OpSession) error {
+		checked = true
+		return nil
+	})).EditConfig(Cfg(`<top/>`)).Run()
+	assert.NoError(t, err, "Not expecting transaction to fail")
+	assert.True(t, checked, "Expected health check")
+
+	sh := ts.SessionHandler(ncs.ID())
+	assert.Equal(t, []string{"lock", "lock", "edit-config", "commit", "commit", "unlock", "unlock"},
+		requestNames(sh), "Unexpected requests")
+	assert.Equal(t, `<confirmed></confirmed><confirm-timeout>60</confirm-timeout>`, sh.Reqs[3].Body, "Expected confirmed commit")
+	assert.Equal(t, ``, sh.Reqs[4].Body, "Expected confirming commit")
+}
+
+func TestTransactionHealthCheckFailure(t *testing.T) {
+	ts := testserver.NewTestNetconfServer(t).WithCapabilities(confirmedCaps)
+	ncs := newOpsSessionWithTestServer(t, ts)
+	defer ncs.Close()
+
+	unhealthy := errors.New("unhealthy")
+	err := NewTransaction(ncs, WithConfirmedCommit(60, func(s OpSession) error {
+		return unhealthy
+	})).EditConfig(Cfg(`<top/>`)).Run()
+
+	var terr *TransactionError
+	assert.True(t, errors.As(err, &terr), "Expecting transaction error")
+	assert.Equal(t, StepHealthCheck, terr.Step, "Unexpected failed step")
+	assert.ErrorIs(t, err, unhealthy, "Expecting health check error")
+	assert.Equal(t, []string{"lock", "lock", "edit-config", "commit", "cancel-commit", "discard-changes", "unlock", "unlock"},
+		requestNames(ts.SessionHandler(ncs.ID())), "Unexpected requests")
+}
+
+func TestTransactionHealthCheckFailureConfirmedCommit10(t *testing.T) {
+	ts := testserver.NewTestNetconfServer(t).WithCapabilities([]string{common.CapBase10, common.CapCandidate,
+		common.CapConfirmedCommit10})
+	ncs := newOpsSessionWithTestServer(t, ts)
+	defer ncs.Close()
+
+	unhealthy := errors.New("unhealthy")
+	err := NewTransaction(ncs, WithConfirmedCommit(60, func(s OpSession) error {
+		return unhealthy
+	})).EditConfig(Cfg(`<top/>`)).Run()
+
+	var terr *TransactionError
+	assert.True(t, errors.As(err, &terr), "Expecting transaction error")
+	assert.Equal(t, StepHealthCheck, terr.Step, "Unexpected failed step")
+	assert.Empty(t, terr.Cleanup, "Not expecting cleanup failures")
+	assert.Equal(t, []string{"lock", "lock", "edit-config", "commit", "discard-changes", "unlock", "unlock"},
+		requestNames(ts.SessionHandler(ncs.ID())), "Expected commit to be left to time out")
+}
+
+func TestTransactionEditFailure(t *testing.T) {
+	ts := testserver.NewTestNetconfServer(t).WithCapabilities(candidateCaps).
+		WithRequestHandler(testserver.EchoRequestHandler).
+		WithRequestHandler(testserver.EchoRequestHandler).
+		WithRequestHandler(testserver.FailingRequestHandler).
+		WithRequestHandler(testserver.EchoRequestHandler).
+		WithRequestHandler(testserver.FailingRequestHandler)
+	ncs := newOpsSessionWithTestServer(t, ts)
+	defer ncs.Close()
+
+	err := NewTransaction(ncs).EditConfig(Cfg(`<top/>`)).Run()
+	assert.EqualError(t, err, "transaction edit-config candidate failed: netconf rpc [error] 'oops'; "+
+		"transaction unlock candidate failed: netconf rpc [error] 'oops'", "Unexpected error")
+
+	var terr *TransactionError
+	assert.True(t, errors.As(err, &terr), "Expecting transaction error")
+	assert.Equal(t, StepEditConfig, terr.Step, "Unexpected failed step")
+	assert.Len(t, terr.Cleanup, 1, "Expecting unlock failure")
+
+	var rpcErrs *common.RPCErrors
+	assert.True(t, errors.As(err, &rpcErrs), "Expecting rpc error")
+	assert.Equal(t, []string{"lock", "lock", "edit-config", "discard-changes", "unlock", "unlock"},
+		requestNames(ts.SessionHandler(ncs.ID())), "Unexpected requests")
+}
+
+func TestTransactionLockFailure(t *testing.T) {
+	ts := testserver.NewTestNetconfServer(t).WithCapabilities(candidateCaps).
+		WithRequestHandler(testserver.EchoRequestHandler).
+		WithRequestHandler(testserver.FailingRequestHandler)
+	ncs := newOpsSessionWithTestServer(t, ts)
+	defer ncs.Close()
+
+	err := NewTransaction(ncs).EditConfig(Cfg(`<top/>`)).Run()
+
+	var terr *TransactionError
+	assert.True(t, errors.As(err, &terr), "Expecting transaction error")
+	assert.Equal(t, StepLock, terr.Step, "Unexpected failed step")
+	assert.Equal(t, CandidateCfg, terr.Datastore, "Unexpected datastore")
+	assert.Equal(t, []string{"lock", "lock", "unlock"}, requestNames(ts.SessionHandler(ncs.ID())), "Unexpected requests")
+}
+
+func TestTransactionUnlockFailure(t *testing.T) {
+	ts := testserver.NewTestNetconfServer(t).WithCapabilities(runningCaps).
+		WithRequestHandler(testserver.EchoRequestHandler).
+		WithRequestHandler(testserver.EchoRequestHandler).
+		WithRequestHandler(testserver.FailingRequestHandler)
+	ncs := newOpsSessionWithTestServer(t, ts)
+	defer ncs.Close()
+
+	err := NewTransaction(ncs).EditConfig(Cfg(`<top/>`)).Run()
+
+	var terr *TransactionError
+	assert.True(t, errors.As(err, &terr), "Expecting transaction error")
+	assert.Equal(t, StepUnlock, terr.Step, "Unexpected failed step")
+	assert.Equal(t, RunningCfg, terr.Datastore, "Unexpected datastore")
+}
+
+func TestTransactionWritableRunning(t *testing.T) {
+	ts := testserver.NewTestNetconfServer(t).WithCapabilities(runningCaps)
+	ncs := newOpsSessionWithTestServer(t, ts)
+	defer ncs.Close()
+
+	err := NewTransaction(ncs).EditConfig(Cfg(`<top/>`)).Run()
+	assert.NoError(t, err, "Not expecting transaction to fail")
+
+	sh := ts.SessionHandler(ncs.ID())
+	assert.Equal(t, []string{"lock", "edit-config", "unlock"}, requestNames(sh), "Unexpected requests")
+	assert.Equal(t, `<target><running/></target><error-option>rollback-on-error</error-option><config><top/></config>`,
+		sh.Reqs[1].Body, "Expected edit of running with rollback")
+}
+
+func TestTransactionCapabilities(t *testing.T) {
+	ts := testserver.NewTestNetconfServer(t).WithCapabilities([]string{common.CapBase10})
+	ncs := newOpsSessionWithTestServer(t, ts)
+	defer ncs.Close()
+
+	err := NewTransaction(ncs).EditConfig(Cfg(`<top/>`)).Run()
+	assert.ErrorIs(t, err, ErrNoWritableDatastore, "Expecting transaction to fail")
+
+	err = NewTransaction(ncs, WithConfirmedCommit(60, func(s OpSession) error { return nil })).Run()
+	assert.ErrorIs(t, err, ErrNoWritableDatastore, "Expecting transaction to fail")
+	assert.Equal(t, 0, ts.SessionHandler(ncs.ID()).ReqCount(), "Not expecting any requests")
+
+	ts = testserver.NewTestNetconfServer(t).WithCapabilities(candidateCaps)
+	ncs = newOpsSessionWithTestServer(t, ts)
+	defer ncs.Close()
+
+	err = NewTransaction(ncs, WithConfirmedCommit(60, func(s OpSession) error { return nil })).Run()
+	assert.ErrorIs(t, err, ErrConfirmedCommitNotSupported, "Expecting transaction to fail")
+	assert.Equal(t, 0, ts.SessionHandler(ncs.ID()).ReqCount(), "Not expecting any requests")
+}
+
+func requestNames(sh *testserver.SessionHandler) (names []string) {
+	for _, req := range sh.Reqs {
+		names = append(names, req.XMLName.Local)
+	}
+	return
+}
@@ -0,0 +1,104 @@
+package ops
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/damianoneill/net/v2/netconf/common"
+)
+
+// Defines the with-defaults retrieval modes described by RFC 6243.
+
+const (
+	// WithDefaultsCap is the with-defaults capability, as advertised by a server.
+	WithDefaultsCap = "urn:ietf:params:netconf:capability:with-defaults:1.0"
+	// WithDefaultsNS is the namespace of the with-defaults parameter.
+	WithDefaultsNS = "urn:ietf:params:xml:ns:yang:ietf-netconf-with-defaults"
+	// DefaultAttrNS is the namespace of the attribute that marks default values in report-all-tagged mode.
+	DefaultAttrNS = "urn:ietf:params:xml:ns:netconf:default:1.0"
+
+	// With Defaults Modes
+	ReportAllMode       = "report-all"
+	ReportAllTaggedMode = "report-all-tagged"
+	TrimMode            = "trim"
+	ExplicitMode        = "explicit"
+)
+
+// DefaultAttr is the name of the attribute that marks a default value in report-all-tagged mode.
+var DefaultAttr = xml.Name{Space: DefaultAttrNS, Local: "default"}
+
+// ErrWithDefaultsNotSupported is returned if a with-defaults mode is requested that the server does not support.
+var ErrWithDefaultsNotSupported = errors.New("with-defaults mode not supported by server")
+
+type WithDefaultsParam struct {
+	XMLName xml.Name `xml:"urn:ietf:params:xml:ns:yang:ietf-netconf-with-defaults with-defaults"`
+	Mode    string   `xml:",chardata"`
+}
+
+// GetOption qualifies a get or get-config operation.
+type GetOption func(*getOptions)
+
+type getOptions struct {
+	withDefaults string
+}
+
+// WithDefaults requests that default values are reported according to the specified mode, such as
+// ReportAllTaggedMode. The mode must be supported by the server, as advertised by its with-defaults capability.
+func WithDefaults(mode string) GetOption {
+	return func(opts *getOptions) {
+		opts.withDefaults = mode
+	}
+}
+
+// TaggedValue can be used to decode a leaf retrieved in report-all-tagged mode, indicating whether the value is a
+// default value.
+type TaggedValue struct {
+	Value   string `xml:",chardata"`
+	Default bool   `xml:"urn:ietf:params:xml:ns:netconf:default:1.0 default,attr"`
+}
+
+// IsDefault reports whether the attributes of an element retrieved in report-all-tagged mode mark it as a
+// default value.
+func IsDefault(attrs []xml.Attr) bool {
+	for _, attr := range attrs {
+		if attr.Name == DefaultAttr {
+			return attr.Value == "true"
+		}
+	}
+	return false
+}
+
+// withDefaultsParam returns the with-defaults parameter defined by the options, or nil if there is none.
+func (s *sImpl) withDefaultsParam(options []GetOption) (*WithDefaultsParam, error) {
+	opts := &getOptions{}
+	for _, opt := range options {
+		opt(opts)
+	}
+	if opts.withDefaults == "" {
+		return nil, nil
+	}
+	if !withDefaultsSupported(s.ServerCapabilities(), opts.withDefaults) {
+		return nil, fmt.Errorf("%w: %s", ErrWithDefaultsNotSupported, opts.withDefaults)
+	}
+	return &WithDefaultsParam{Mode: opts.withDefaults}, nil
+}
+
+// withDefaultsSupported reports whether the with-defaults capability in caps includes mode as its basic-mode, or
+// as one of its also-supported modes.
+func withDefaultsSupported(caps []string, mode string) bool {
+	c := common.ParseCapabilities(caps).Get(WithDefaultsCap)
+	if c == nil {
+		return false
+	}
+	if c.Params.Get("basic-mode") == mode {
+		return true
+	}
+	for _, m := range strings.Split(c.Params.Get("also-supported"), ",") {
+		if m == mode {
+			return true
+		}
+	}
+	return false
+}
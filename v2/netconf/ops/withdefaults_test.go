@@ -0,0 +1,81 @@
+package ops
+
+import (
+	"encoding/xml"
+	"testing"
+
+	"github.com/damianoneill/net/v2/netconf/common"
+	"github.com/damianoneill/net/v2/netconf/testserver"
+
+	assert "github.com/stretchr/testify/require"
+)
+
+var withDefaultsCaps = []string{
+	common.CapBase10, common.CapXpath,
+	WithDefaultsCap + "?basic-mode=explicit&also-supported=report-all,report-all-tagged",
+}
+
+func TestGetWithDefaults(t *testing.T) {
+	ncs := newOpsSessionWithTestServer(t, testserver.NewTestNetconfServer(t).WithCapabilities(withDefaultsCaps))
+	defer ncs.Close()
+
+	wd := `<with-defaults xmlns="urn:ietf:params:xml:ns:yang:ietf-netconf-with-defaults">report-all-tagged</with-defaults>`
+
+	var result string
+	err := ncs.GetSubtree(`<top/>`, &result, WithDefaults(ReportAllTaggedMode))
+	assert.NoError(t, err, "Not expecting call to fail")
+	assert.Equal(t, `<filter type="subtree"><top/></filter>`+wd, result, "Unexpected request content")
+
+	err = ncs.GetXpath("/top", nil, &result, WithDefaults(ReportAllTaggedMode))
+	assert.NoError(t, err, "Not expecting call to fail")
+	assert.Equal(t, `<filter  type="xpath" select="/top"/>`+wd, result, "Unexpected request content")
+
+	err = ncs.GetConfigSubtree(`<top/>`, RunningCfg, &result, WithDefaults(ExplicitMode))
+	assert.NoError(t, err, "Not expecting call to fail")
+	assert.Equal(t, `<source><running/></source><filter type="subtree"><top/></filter>`+
+		`<with-defaults xmlns="urn:ietf:params:xml:ns:yang:ietf-netconf-with-defaults">explicit</with-defaults>`,
+		result, "Unexpected request content")
+
+	err = ncs.GetConfigXpath("/top", nil, RunningCfg, &result, WithDefaults(ReportAllMode))
+	assert.NoError(t, err, "Not expecting call to fail")
+	assert.Equal(t, `<source><running/></source><filter  type="xpath" select="/top"/>`+
+		`<with-defaults xmlns="urn:ietf:params:xml:ns:yang:ietf-netconf-with-defaults">report-all</with-defaults>`,
+		result, "Unexpected request content")
+}
+
+func TestGetWithUnsupportedDefaults(t *testing.T) {
+	ts := testserver.NewTestNetconfServer(t).WithCapabilities(withDefaultsCaps)
+	ncs := newOpsSessionWithTestServer(t, ts)
+	defer ncs.Close()
+
+	var result string
+	err := ncs.GetSubtree(`<top/>`, &result, WithDefaults(TrimMode))
+	assert.ErrorIs(t, err, ErrWithDefaultsNotSupported, "Expecting call to fail")
+	assert.EqualError(t, err, "with-defaults mode not supported by server: trim")
+	assert.Equal(t, 0, ts.SessionHandler(ncs.ID()).ReqCount(), "Not expecting request to be sent")
+
+	ncs2 := newOpsSessionWithTestServer(t, testserver.NewTestNetconfServer(t))
+	defer ncs2.Close()
+	err = ncs2.GetConfigSubtree(`<top/>`, RunningCfg, &result, WithDefaults(ReportAllMode))
+	assert.ErrorIs(t, err, ErrWithDefaultsNotSupported, "Expecting call to fail")
+}
+
+func TestDecodeTaggedDefaults(t *testing.T) {
+	type iface struct {
+		Name    string      `xml:"name"`
+		MTU     TaggedValue `xml:"mtu"`
+		Enabled TaggedValue `xml:"enabled"`
+	}
+
+	reply := `<data><interface xmlns:wd="urn:ietf:params:xml:ns:netconf:default:1.0">` +
+		`<name>eth0</name><mtu>9000</mtu><enabled wd:default="true">true</enabled></interface></data>`
+
+	result := &iface{}
+	err := xml.Unmarshal([]byte(reply), &Data{Body: result})
+	assert.NoError(t, err, "Not expecting decode to fail")
+	assert.Equal(t, TaggedValue{Value: "9000"}, result.MTU, "Expected explicit value")
+	assert.Equal(t, TaggedValue{Value: "true", Default: true}, result.Enabled, "Expected default value")
+
+	assert.True(t, IsDefault([]xml.Attr{{Name: DefaultAttr, Value: "true"}}))
+	assert.False(t, IsDefault([]xml.Attr{{Name: xml.Name{Local: "default"}, Value: "true"}}))
+}
@@ -0,0 +1,248 @@
+package ops
+
+import (
+	"encoding/xml"
+	"errors"
+	"strings"
+
+	"github.com/damianoneill/net/v2/netconf/common"
+)
+
+// Defines retrieval of the YANG library described by RFC 8525, and its predecessor described by RFC 7895.
+
+const (
+	// YangLibraryNS is the namespace of the ietf-yang-library module.
+	YangLibraryNS = "urn:ietf:params:xml:ns:yang:ietf-yang-library"
+
+	// Module conformance types.
+	ConformanceImplement = "implement"
+	ConformanceImport    = "import"
+
+	// legacyModuleSetName is the name of the module set that holds the modules reported by modules-state.
+	legacyModuleSetName = "modules-state"
+)
+
+// YangLibrary describes the YANG modules, and the datastores that they apply to, supported by a server.
+type YangLibrary struct {
+	XMLName    xml.Name           `xml:"urn:ietf:params:xml:ns:yang:ietf-yang-library yang-library"`
+	ModuleSets []ModuleSet        `xml:"module-set"`
+	Schemas    []LibrarySchema    `xml:"schema"`
+	Datastores []LibraryDatastore `xml:"datastore"`
+	// Identifies the content of the library; it changes whenever the content changes.
+	ContentID string `xml:"content-id"`
+}
+
+// ModuleSet defines a set of modules, some of which are implemented by the server, and others are only imported.
+type ModuleSet struct {
+	Name              string          `xml:"name"`
+	Modules           []LibraryModule `xml:"module"`
+	ImportOnlyModules []LibraryModule `xml:"import-only-module"`
+}
+
+// LibraryModule describes a YANG module in the library.
+type LibraryModule struct {
+	Name      string `xml:"name"`
+	Revision  string `xml:"revision"`
+	Namespace string `xml:"namespace"`
+	// The URLs from which the module can be retrieved.
+	Locations  []string           `xml:"location"`
+	Submodules []LibrarySubmodule `xml:"submodule"`
+	// The features of the module supported by the server.
+	Features []string `xml:"feature"`
+	// The names of the modules that contain deviations to the module.
+	Deviations []string `xml:"deviation"`
+	// Whether the module is implemented (ConformanceImplement) or only imported (ConformanceImport).
+	ConformanceType string `xml:"-"`
+}
+
+// LibrarySubmodule describes a YANG submodule of a module in the library.
+type LibrarySubmodule struct {
+	Name      string   `xml:"name"`
+	Revision  string   `xml:"revision"`
+	Locations []string `xml:"location"`
+}
+
+// LibrarySchema defines a schema, which is the union of a set of module sets.
+type LibrarySchema struct {
+	Name       string   `xml:"name"`
+	ModuleSets []string `xml:"module-set"`
+}
+
+// LibraryDatastore identifies the schema that applies to a datastore.
+type LibraryDatastore struct {
+	// The datastore identity, such as ds:running.
+	Name   string `xml:"name"`
+	Schema string `xml:"schema"`
+}
+
+// ModuleSet returns the named module set, or nil if it is not present.
+func (y *YangLibrary) ModuleSet(name string) *ModuleSet {
+	for i := range y.ModuleSets {
+		if y.ModuleSets[i].Name == name {
+			return &y.ModuleSets[i]
+		}
+	}
+	return nil
+}
+
+// Modules returns the modules, both implemented and import-only, of the schema that applies to the datastore,
+// such as OperationalCfg. If the library does not define any datastores, as is the case for a library retrieved
+// from modules-state, the modules of every module set are returned.
+func (y *YangLibrary) Modules(datastore string) []LibraryModule {
+	var setNames []string
+	if len(y.Datastores) == 0 {
+		for i := range y.ModuleSets {
+			setNames = append(setNames, y.ModuleSets[i].Name)
+		}
+	}
+	for _, ds := range y.Datastores {
+		if identityName(ds.Name) != datastore {
+			continue
+		}
+		for _, schema := range y.Schemas {
+			if schema.Name == ds.Schema {
+				setNames = append(setNames, schema.ModuleSets...)
+			}
+		}
+	}
+
+	var modules []LibraryModule
+	for _, name := range setNames {
+		if set := y.ModuleSet(name); set != nil {
+			modules = append(modules, set.Modules...)
+			modules = append(modules, set.ImportOnlyModules...)
+		}
+	}
+	return modules
+}
+
+// ModulesState describes the YANG modules supported by a server, as defined by RFC 7895.
+type ModulesState struct {
+	XMLName     xml.Name       `xml:"urn:ietf:params:xml:ns:yang:ietf-yang-library modules-state"`
+	ModuleSetID string         `xml:"module-set-id"`
+	Modules     []LegacyModule `xml:"module"`
+}
+
+// LegacyModule describes a YANG module in the modules-state list.
+type LegacyModule struct {
+	Name      string   `xml:"name"`
+	Revision  string   `xml:"revision"`
+	Schema    string   `xml:"schema"`
+	Namespace string   `xml:"namespace"`
+	Features  []string `xml:"feature"`
+	// The modules that contain deviations to the module.
+	Deviations      []ModuleRef       `xml:"deviation"`
+	ConformanceType string            `xml:"conformance-type"`
+	Submodules      []LegacySubmodule `xml:"submodule"`
+}
+
+// ModuleRef identifies a revision of a module.
+type ModuleRef struct {
+	Name     string `xml:"name"`
+	Revision string `xml:"revision"`
+}
+
+// LegacySubmodule describes a YANG submodule of a module in the modules-state list.
+type LegacySubmodule struct {
+	Name     string `xml:"name"`
+	Revision string `xml:"revision"`
+	Schema   string `xml:"schema"`
+}
+
+// YangLibrary returns the modules-state content as a YangLibrary, with a single module set holding every module.
+func (ms *ModulesState) YangLibrary() *YangLibrary {
+	set := ModuleSet{Name: legacyModuleSetName}
+	for i := range ms.Modules {
+		lm := &ms.Modules[i]
+		m := LibraryModule{
+			Name:            lm.Name,
+			Revision:        lm.Revision,
+			Namespace:       lm.Namespace,
+			Locations:       optionalList(lm.Schema),
+			Features:        lm.Features,
+			ConformanceType: lm.ConformanceType,
+		}
+		for _, d := range lm.Deviations {
+			m.Deviations = append(m.Deviations, d.Name)
+		}
+		for _, sm := range lm.Submodules {
+			m.Submodules = append(m.Submodules,
+				LibrarySubmodule{Name: sm.Name, Revision: sm.Revision, Locations: optionalList(sm.Schema)})
+		}
+		if m.ConformanceType == ConformanceImport {
+			set.ImportOnlyModules = append(set.ImportOnlyModules, m)
+		} else {
+			set.Modules = append(set.Modules, m)
+		}
+	}
+	return &YangLibrary{ModuleSets: []ModuleSet{set}, ContentID: ms.ModuleSetID}
+}
+
+func (s *sImpl) GetYangLibrary() (*YangLibrary, error) {
+	caps := common.ParseCapabilities(s.ServerCapabilities())
+	switch {
+	case caps.HasCapability(common.CapYangLibrary11):
+		return s.getYangLibrary()
+	case caps.HasCapability(common.CapYangLibrary10):
+		return s.getModulesState()
+	}
+
+	// The library is not advertised, so try the current library before falling back to the legacy one.
+	lib, err := s.getYangLibrary()
+	if err == nil && len(lib.ModuleSets) > 0 {
+		return lib, nil
+	}
+	var rpcErrs *common.RPCErrors
+	if err != nil && !errors.As(err, &rpcErrs) {
+		return nil, err
+	}
+	return s.getModulesState()
+}
+
+func (s *sImpl) getYangLibrary() (*YangLibrary, error) {
+	lib := &YangLibrary{}
+	if err := s.handleGetRequest(createGetYangLibraryRequest(), lib); err != nil {
+		return nil, err
+	}
+	for i := range lib.ModuleSets {
+		set := &lib.ModuleSets[i]
+		for j := range set.Modules {
+			set.Modules[j].ConformanceType = ConformanceImplement
+		}
+		for j := range set.ImportOnlyModules {
+			set.ImportOnlyModules[j].ConformanceType = ConformanceImport
+		}
+	}
+	return lib, nil
+}
+
+func (s *sImpl) getModulesState() (*YangLibrary, error) {
+	ms := &ModulesState{}
+	if err := s.handleGetRequest(createGetModulesStateRequest(), ms); err != nil {
+		return nil, err
+	}
+	return ms.YangLibrary(), nil
+}
+
+func createGetYangLibraryRequest() *GetReq {
+	return createGetSubtreeRequest(`<yang-library xmlns="urn:ietf:params:xml:ns:yang:ietf-yang-library"/>`)
+}
+
+func createGetModulesStateRequest() *GetReq {
+	return createGetSubtreeRequest(`<modules-state xmlns="urn:ietf:params:xml:ns:yang:ietf-yang-library"/>`)
+}
+
+// identityName returns the name of an identity value, without its prefix.
+func identityName(value string) string {
+	if i := strings.IndexByte(value, ':'); i >= 0 {
+		return value[i+1:]
+	}
+	return value
+}
+
+func optionalList(value string) []string {
+	if value == "" {
+		return nil
+	}
+	return []string{value}
+}
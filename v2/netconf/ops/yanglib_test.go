@@ -0,0 +1,133 @@
+package ops
+
+import (
+	"testing"
+
+	"github.com/damianoneill/net/v2/netconf/common"
+
+	assert "github.com/stretchr/testify/require"
+)
+
+const yangLibraryReply = `<data><yang-library xmlns="urn:ietf:params:xml:ns:yang:ietf-yang-library">
+<module-set><name>config</name>
+<module><name>ietf-interfaces</name><revision>2018-02-20</revision>
+<namespace>urn:ietf:params:xml:ns:yang:ietf-interfaces</namespace>
+<location>https://example.com/ietf-interfaces.yang</location>
+<feature>pre-provisioning</feature><deviation>example-deviations</deviation></module>
+<module><name>example-system</name><namespace>urn:example:system</namespace>
+<submodule><name>example-system-types</name><revision>2020-01-01</revision></submodule></module>
+<import-only-module><name>ietf-yang-types</name><revision>2013-07-15</revision>
+<namespace>urn:ietf:params:xml:ns:yang:ietf-yang-types</namespace></import-only-module>
+</module-set>
+<module-set><name>state</name>
+<module><name>example-state</name><namespace>urn:example:state</namespace></module>
+</module-set>
+<schema><name>config-schema</name><module-set>config</module-set></schema>
+<schema><name>state-schema</name><module-set>config</module-set><module-set>state</module-set></schema>
+<datastore xmlns:ds="urn:ietf:params:xml:ns:yang:ietf-datastores"><name>ds:running</name><schema>config-schema</schema></datastore>
+<datastore xmlns:ds="urn:ietf:params:xml:ns:yang:ietf-datastores"><name>ds:operational</name><schema>state-schema</schema></datastore>
+<content-id>75a43df9bd56b92aacc156a2958fbe12312fb285</content-id>
+</yang-library></data>`
+
+const modulesStateReply = `<data><modules-state xmlns="urn:ietf:params:xml:ns:yang:ietf-yang-library">
+<module-set-id>14e2ab5dc325f6d86f743e8d3ade233f1a61a899</module-set-id>
+<module><name>ietf-interfaces</name><revision>2014-05-08</revision>
+<schema>https://example.com/ietf-interfaces.yang</schema><namespace>urn:ietf:params:xml:ns:yang:ietf-interfaces</namespace>
+<feature>arbitrary-names</feature>
+<deviation><name>example-deviations</name><revision>2020-01-01</revision></deviation>
+<conformance-type>implement</conformance-type>
+<submodule><name>ietf-interfaces-types</name><revision>2014-05-08</revision><schema>https://example.com/types.yang</schema></submodule>
+</module>
+<module><name>ietf-yang-types</name><revision>2013-07-15</revision>
+<namespace>urn:ietf:params:xml:ns:yang:ietf-yang-types</namespace><conformance-type>import</conformance-type></module>
+</modules-state></data>`
+
+func TestGetYangLibrary(t *testing.T) {
+	ncs, mcli := newOpsSessionWithMockClient(t)
+	mcli.On("ServerCapabilities").Return([]string{common.CapBase11, common.CapYangLibrary11 + "?revision=2019-01-04&content-id=1"})
+	mcli.On("Execute", createGetYangLibraryRequest()).Return(&common.RPCReply{Data: yangLibraryReply}, nil)
+
+	lib, err := ncs.GetYangLibrary()
+	assert.NoError(t, err, "Not expecting call to fail")
+	assert.Equal(t, "75a43df9bd56b92aacc156a2958fbe12312fb285", lib.ContentID, "Unexpected content id")
+	assert.Len(t, lib.ModuleSets, 2, "Unexpected module sets")
+
+	config := lib.ModuleSet("config")
+	assert.Equal(t, LibraryModule{
+		Name:            "ietf-interfaces",
+		Revision:        "2018-02-20",
+		Namespace:       "urn:ietf:params:xml:ns:yang:ietf-interfaces",
+		Locations:       []string{"https://example.com/ietf-interfaces.yang"},
+		Features:        []string{"pre-provisioning"},
+		Deviations:      []string{"example-deviations"},
+		ConformanceType: ConformanceImplement,
+	}, config.Modules[0], "Unexpected module")
+	assert.Equal(t, []LibrarySubmodule{{Name: "example-system-types", Revision: "2020-01-01"}}, config.Modules[1].Submodules,
+		"Unexpected submodules")
+	assert.Equal(t, ConformanceImport, config.ImportOnlyModules[0].ConformanceType, "Unexpected conformance type")
+
+	assert.Len(t, lib.Modules(RunningCfg), 3, "Unexpected running modules")
+	assert.Len(t, lib.Modules(OperationalCfg), 4, "Unexpected operational modules")
+	assert.Empty(t, lib.Modules(StartupCfg), "Not expecting startup modules")
+	mcli.AssertExpectations(t)
+}
+
+func TestGetYangLibraryModulesState(t *testing.T) {
+	ncs, mcli := newOpsSessionWithMockClient(t)
+	mcli.On("ServerCapabilities").Return([]string{common.CapBase10, common.CapYangLibrary10 + "?revision=2016-06-21&module-set-id=1"})
+	mcli.On("Execute", createGetModulesStateRequest()).Return(&common.RPCReply{Data: modulesStateReply}, nil)
+
+	lib, err := ncs.GetYangLibrary()
+	assert.NoError(t, err, "Not expecting call to fail")
+	assertModulesState(t, lib)
+	mcli.AssertExpectations(t)
+}
+
+func TestGetYangLibraryFallback(t *testing.T) {
+	ncs, mcli := newOpsSessionWithMockClient(t)
+	mcli.On("ServerCapabilities").Return([]string{common.CapBase10})
+	mcli.On("Execute", createGetYangLibraryRequest()).
+		Return(nil, &common.RPCErrors{Errors: []common.RPCError{{Tag: "unknown-element", Severity: "error"}}})
+	mcli.On("Execute", createGetModulesStateRequest()).Return(&common.RPCReply{Data: modulesStateReply}, nil)
+
+	lib, err := ncs.GetYangLibrary()
+	assert.NoError(t, err, "Not expecting call to fail")
+	assertModulesState(t, lib)
+	mcli.AssertExpectations(t)
+}
+
+func TestGetYangLibraryNotAdvertised(t *testing.T) {
+	ncs, mcli := newOpsSessionWithMockClient(t)
+	mcli.On("ServerCapabilities").Return([]string{common.CapBase10})
+	mcli.On("Execute", createGetYangLibraryRequest()).Return(&common.RPCReply{Data: yangLibraryReply}, nil)
+
+	lib, err := ncs.GetYangLibrary()
+	assert.NoError(t, err, "Not expecting call to fail")
+	assert.Len(t, lib.ModuleSets, 2, "Unexpected module sets")
+	mcli.AssertExpectations(t)
+}
+
+func assertModulesState(t *testing.T, lib *YangLibrary) {
+	assert.Equal(t, "14e2ab5dc325f6d86f743e8d3ade233f1a61a899", lib.ContentID, "Unexpected content id")
+	assert.Equal(t, []ModuleSet{{
+		Name: "modules-state",
+		Modules: []LibraryModule{{
+			Name:            "ietf-interfaces",
+			Revision:        "2014-05-08",
+			Namespace:       "urn:ietf:params:xml:ns:yang:ietf-interfaces",
+			Locations:       []string{"https://example.com/ietf-interfaces.yang"},
+			Features:        []string{"arbitrary-names"},
+			Deviations:      []string{"example-deviations"},
+			ConformanceType: ConformanceImplement,
+			Submodules: []LibrarySubmodule{{Name: "ietf-interfaces-types", Revision: "2014-05-08",
+				Locations: []string{"https://example.com/types.yang"}}},
+		}},
+		ImportOnlyModules: []LibraryModule{{
+			Name:            "ietf-yang-types",
+			Revision:        "2013-07-15",
+			Namespace:       "urn:ietf:params:xml:ns:yang:ietf-yang-types",
+			ConformanceType: ConformanceImport,
+		}},
+	}}, lib.ModuleSets, "Unexpected module sets")
+	assert.Len(t, lib.Modules(RunningCfg), 2, "Expected all modules")
+}
@@ -0,0 +1,197 @@
+package ops
+
+import (
+	"errors"
+	"strings"
+)
+
+// Defines a minimal scanner of the statements of a YANG module (RFC 7950 section 6).
+
+var errYangSyntax = errors.New("invalid yang statement syntax")
+
+// yangStmt is a YANG statement, with its argument and substatements.
+type yangStmt struct {
+	keyword string
+	arg     string
+	subs    []*yangStmt
+}
+
+// sub returns the first substatement with the keyword, or nil if there is none.
+func (s *yangStmt) sub(keyword string) *yangStmt {
+	for _, sub := range s.subs {
+		if sub.keyword == keyword {
+			return sub
+		}
+	}
+	return nil
+}
+
+// parseYangModule returns the module or submodule statement of the text of a YANG module.
+func parseYangModule(text string) (*yangStmt, error) {
+	tokens, err := scanYang(text)
+	if err != nil {
+		return nil, err
+	}
+	i := 0
+	stmts, err := parseYangStmts(tokens, &i, false)
+	if err != nil {
+		return nil, err
+	}
+	for _, stmt := range stmts {
+		if stmt.keyword == "module" || stmt.keyword == "submodule" {
+			return stmt, nil
+		}
+	}
+	return nil, errYangSyntax
+}
+
+// yangToken is a token of a YANG module; the ";", "{" and "}" delimiters are unquoted tokens.
+type yangToken struct {
+	value  string
+	quoted bool
+}
+
+func (t yangToken) is(delimiter string) bool {
+	return !t.quoted && t.value == delimiter
+}
+
+// parseYangStmts parses statements until the end of the tokens or, if nested is true, the "}" that ends the
+// enclosing statement.
+func parseYangStmts(tokens []yangToken, i *int, nested bool) ([]*yangStmt, error) {
+	var stmts []*yangStmt
+	for {
+		if *i == len(tokens) {
+			if nested {
+				return nil, errYangSyntax
+			}
+			return stmts, nil
+		}
+		if tokens[*i].is("}") {
+			if !nested {
+				return nil, errYangSyntax
+			}
+			return stmts, nil
+		}
+		if tokens[*i].quoted || tokens[*i].is(";") || tokens[*i].is("{") {
+			return nil, errYangSyntax
+		}
+
+		stmt := &yangStmt{keyword: tokens[*i].value}
+		*i++
+
+		// The argument, which may be the concatenation of several quoted strings.
+		var arg strings.Builder
+		for ; *i < len(tokens) && !tokens[*i].is(";") && !tokens[*i].is("{"); *i++ {
+			switch {
+			case tokens[*i].is("}"):
+				return nil, errYangSyntax
+			case !tokens[*i].is("+"):
+				arg.WriteString(tokens[*i].value)
+			}
+		}
+		stmt.arg = arg.String()
+		if *i == len(tokens) {
+			return nil, errYangSyntax
+		}
+
+		if tokens[*i].is("{") {
+			*i++
+			subs, err := parseYangStmts(tokens, i, true)
+			if err != nil {
+				return nil, err
+			}
+			stmt.subs = subs
+		}
+		// Consume the ";" or "}" that ends the statement.
+		*i++
+		stmts = append(stmts, stmt)
+	}
+}
+
+// scanYang splits the text of a YANG module into tokens, discarding comments and unquoting strings.
+func scanYang(text string) ([]yangToken, error) {
+	var tokens []yangToken
+	for i := 0; i < len(text); {
+		c := text[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case strings.HasPrefix(text[i:], "//"):
+			end := strings.IndexByte(text[i:], '\n')
+			if end < 0 {
+				return tokens, nil
+			}
+			i += end + 1
+		case strings.HasPrefix(text[i:], "/*"):
+			end := strings.Index(text[i+2:], "*/")
+			if end < 0 {
+				return nil, errYangSyntax
+			}
+			i += end + 4
+		case c == ';' || c == '{' || c == '}':
+			tokens = append(tokens, yangToken{value: string(c)})
+			i++
+		case c == '\'':
+			end := strings.IndexByte(text[i+1:], '\'')
+			if end < 0 {
+				return nil, errYangSyntax
+			}
+			tokens = append(tokens, yangToken{value: text[i+1 : i+1+end], quoted: true})
+			i += end + 2
+		case c == '"':
+			value, n, err := scanDoubleQuoted(text[i:])
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, yangToken{value: value, quoted: true})
+			i += n
+		default:
+			n := unquotedLength(text[i:])
+			tokens = append(tokens, yangToken{value: text[i : i+n]})
+			i += n
+		}
+	}
+	return tokens, nil
+}
+
+// scanDoubleQuoted returns the value of the double-quoted string at the start of text, and its length in text.
+func scanDoubleQuoted(text string) (string, int, error) {
+	var value strings.Builder
+	for i := 1; i < len(text); i++ {
+		switch text[i] {
+		case '"':
+			return value.String(), i + 1, nil
+		case '\\':
+			if i+1 == len(text) {
+				return "", 0, errYangSyntax
+			}
+			i++
+			switch text[i] {
+			case 'n':
+				value.WriteByte('\n')
+			case 't':
+				value.WriteByte('\t')
+			default:
+				value.WriteByte(text[i])
+			}
+		default:
+			value.WriteByte(text[i])
+		}
+	}
+	return "", 0, errYangSyntax
+}
+
+// unquotedLength returns the length of the unquoted string at the start of text.
+func unquotedLength(text string) int {
+	for i := 0; i < len(text); i++ {
+		switch text[i] {
+		case ' ', '\t', '\n', '\r', ';', '{', '}', '"', '\'':
+			return i
+		case '/':
+			if i > 0 && (strings.HasPrefix(text[i:], "//") || strings.HasPrefix(text[i:], "/*")) {
+				return i
+			}
+		}
+	}
+	return len(text)
+}
@@ -3,6 +3,7 @@ package testserver
 
 import (
 	"encoding/xml"
+	"errors"
 	"sync"
 	"time"
 
@@ -51,6 +52,8 @@ type SessionHandler struct {
 	// Records executed requests.
 	reqMutex sync.Mutex
 	Reqs     []RPCRequest
+	// The number of requests that could not be parsed.
+	malformed int
 }
 
 // rpcRequestMessage and rpcRequest represent an RPC request from a client, where the element type of the
@@ -117,6 +120,27 @@ var FailingRequestHandler = func(h *SessionHandler, req *rpcRequestMessage) {
 	assert.NoError(h.t, err, "Failed to encode response")
 }
 
+// MultipleErrorsRequestHandler replies to a request with a warning, and two errors, the first indicating that a lock is
+// held by session 42.
+var MultipleErrorsRequestHandler = func(h *SessionHandler, req *rpcRequestMessage) {
+	reply := &RPCReplyMessage{
+		MessageID: req.MessageID,
+		Errors: []common.RPCError{
+			{Type: "application", Tag: "operation-failed", Severity: "warning", Message: "careful"},
+			{
+				Type: "protocol", Tag: "lock-denied", Severity: "error", Message: "locked",
+				ErrorInfo: &common.ErrorInfo{SessionID: 42},
+			},
+			{
+				Type: "protocol", Tag: "invalid-value", Severity: "error", AppTag: "too-large", Message: "bad value",
+				Path: "/top/value", ErrorInfo: &common.ErrorInfo{BadElement: "value"},
+			},
+		},
+	}
+	err := h.encode(reply)
+	assert.NoError(h.t, err, "Failed to encode response")
+}
+
 // CloseRequestHandler closes the transport channel on request receipt.
 var CloseRequestHandler = func(h *SessionHandler, req *rpcRequestMessage) {
 	_ = h.ch.Close()
@@ -125,6 +149,28 @@ var CloseRequestHandler = func(h *SessionHandler, req *rpcRequestMessage) {
 // IgnoreRequestHandler does in nothing on receipt of a request.
 var IgnoreRequestHandler = func(h *SessionHandler, req *rpcRequestMessage) {}
 
+// NoMessageIDRequestHandler responds to a request in the same way as the EchoRequestHandler, but
+// omits the message-id from the reply, as some legacy devices do.
+var NoMessageIDRequestHandler = func(h *SessionHandler, req *rpcRequestMessage) {
+	EchoRequestHandler(h, &rpcRequestMessage{XMLName: req.XMLName, Request: req.Request})
+}
+
+// UnsolicitedReplyRequestHandler sends a reply with an unknown message-id, before responding to the
+// request in the same way as the EchoRequestHandler.
+var UnsolicitedReplyRequestHandler = func(h *SessionHandler, req *rpcRequestMessage) {
+	EchoRequestHandler(h, &rpcRequestMessage{XMLName: req.XMLName, MessageID: "unsolicited", Request: req.Request})
+	EchoRequestHandler(h, req)
+}
+
+// DelayedRequestHandler delivers a handler that responds to a request in the same way as the
+// EchoRequestHandler, after waiting for the specified delay.
+func DelayedRequestHandler(delay time.Duration) RequestHandler {
+	return func(h *SessionHandler, req *rpcRequestMessage) {
+		time.Sleep(delay)
+		EchoRequestHandler(h, req)
+	}
+}
+
 // SmartRequesttHandler responds to common requests with trivial content.
 var SmartRequesttHandler = func(h *SessionHandler, req *rpcRequestMessage) {
 	data := replyData{Data: responseFor(req)}
@@ -197,7 +243,15 @@ func (h *SessionHandler) WaitStart() {
 
 // SendNotification sends a notification message with the supplied body to the client.
 func (h *SessionHandler) SendNotification(body string) *SessionHandler {
-	nm := &NotifyMessage{EventTime: time.Now().String(), Data: body}
+	nm := &NotifyMessage{EventTime: time.Now().Format(time.RFC3339Nano), Data: body}
+	err := h.encode(nm)
+	assert.NoError(h.t, err, "Failed to send server notification")
+	return h
+}
+
+// SendNotificationAt sends a notification message with the supplied event time and body to the client.
+func (h *SessionHandler) SendNotificationAt(eventTime time.Time, body string) *SessionHandler {
+	nm := &NotifyMessage{EventTime: eventTime.Format(time.RFC3339Nano), Data: body}
 	err := h.encode(nm)
 	assert.NoError(h.t, err, "Failed to send server notification")
 	return h
@@ -262,13 +316,35 @@ func (h *SessionHandler) handleHello(token xml.StartElement) {
 
 func (h *SessionHandler) handleRPC(token xml.StartElement) {
 	request := &rpcRequestMessage{}
-	h.decodeElement(&request, &token)
+	err := h.dec.DecodeElement(&request, &token)
+	var serr *xml.SyntaxError
+	if errors.As(err, &serr) {
+		// The request cannot be parsed, so is rejected without a message id; the session then ends, as no
+		// further input can be decoded.
+		h.malformedMessage()
+		return
+	}
+	assert.NoError(h.t, err, "DecodeElement failed")
 
 	h.reqLogger(request.Request)
 	reqh := h.nextReqHandler()
 	reqh(h, request)
 }
 
+func (h *SessionHandler) malformedMessage() {
+	h.reqMutex.Lock()
+	h.malformed++
+	h.reqMutex.Unlock()
+
+	reply := &RPCReplyMessage{
+		Errors: []common.RPCError{
+			{Type: "rpc", Tag: "malformed-message", Severity: "error", Message: "malformed message"},
+		},
+	}
+	err := h.encode(reply)
+	assert.NoError(h.t, err, "Failed to encode response")
+}
+
 func (h *SessionHandler) decodeElement(v interface{}, start *xml.StartElement) {
 	err := h.dec.DecodeElement(v, start)
 	assert.NoError(h.t, err, "DecodeElement failed")
@@ -297,13 +373,24 @@ func (h *SessionHandler) reqLogger(r RPCRequest) {
 	h.Reqs = append(h.Reqs, r)
 }
 
+// MalformedCount delivers the number of requests rejected by the handler because they could not be parsed.
+func (h *SessionHandler) MalformedCount() int {
+	h.reqMutex.Lock()
+	defer h.reqMutex.Unlock()
+	return h.malformed
+}
+
 // ReqCount delivers the number of requests received by the handler.
 func (h *SessionHandler) ReqCount() int {
+	h.reqMutex.Lock()
+	defer h.reqMutex.Unlock()
 	return len(h.Reqs)
 }
 
 // LastReq delivers the last request received by the handler, or nil if no requests have been received.
 func (h *SessionHandler) LastReq() *RPCRequest {
+	h.reqMutex.Lock()
+	defer h.reqMutex.Unlock()
 	count := len(h.Reqs)
 	if count > 0 {
 		return &h.Reqs[count-1]
@@ -3,6 +3,7 @@ package testserver
 import (
 	"fmt"
 	"runtime"
+	"sync"
 	"sync/atomic"
 
 	"github.com/damianoneill/net/v2/netconf/common"
@@ -21,6 +22,7 @@ const (
 // be invoked to handle netconf messages.
 type TestNCServer struct {
 	*SSHServer
+	mu              sync.Mutex
 	sessionHandlers map[uint64]*SessionHandler
 	reqHandlers     []RequestHandler
 	caps            []string
@@ -51,20 +53,26 @@ func (ncs *TestNCServer) newFactory() HandlerFactory {
 	return func(t assert.TestingT) SSHHandler {
 		sid := atomic.AddUint64(&ncs.nextSid, 1)
 		sess := newSessionHandler(ncs, sid)
+		ncs.mu.Lock()
 		ncs.sessionHandlers[sid] = sess
-		sess.capabilities = ncs.caps
 		sess.reqHandlers = ncs.reqHandlers
+		ncs.mu.Unlock()
+		sess.capabilities = ncs.caps
 		return sess
 	}
 }
 
 // LastHandler delivers the most recently instantiated session handler.
 func (ncs *TestNCServer) LastHandler() *SessionHandler {
-	return ncs.sessionHandlers[ncs.nextSid]
+	ncs.mu.Lock()
+	defer ncs.mu.Unlock()
+	return ncs.sessionHandlers[atomic.LoadUint64(&ncs.nextSid)]
 }
 
 // WithRequestHandler adds a request handler to the netconf session.
 func (ncs *TestNCServer) WithRequestHandler(rh RequestHandler) *TestNCServer {
+	ncs.mu.Lock()
+	defer ncs.mu.Unlock()
 	ncs.reqHandlers = append(ncs.reqHandlers, rh)
 	return ncs
 }
@@ -77,12 +85,14 @@ func (ncs *TestNCServer) WithCapabilities(caps []string) *TestNCServer {
 
 // Close closes any active transport to the test server and prevents subsequent connections.
 func (ncs *TestNCServer) Close() {
+	ncs.mu.Lock()
 	for k, v := range ncs.sessionHandlers {
 		if v.ch != nil {
 			v.Close()
 			ncs.sessionHandlers[k] = nil
 		}
 	}
+	ncs.mu.Unlock()
 	ncs.SSHServer.Close()
 }
 
@@ -100,7 +110,9 @@ func (ncs *TestNCServer) FailNow() {
 
 // SessionHandler delivers the netconf session handler associated with the specified session id.
 func (ncs *TestNCServer) SessionHandler(id uint64) *SessionHandler {
+	ncs.mu.Lock()
 	sh, ok := ncs.sessionHandlers[id]
+	ncs.mu.Unlock()
 	if !ok {
 		ncs.tctx.Errorf("Failed to get handler for session %d", id)
 		ncs.tctx.FailNow()
@@ -82,38 +82,44 @@ func acceptConnections(t assert.TestingT, listener net.Listener, config *ssh.Ser
 			return
 		}
 
-		_, chch, reqch, err := ssh.NewServerConn(nConn, config)
-		if err != nil {
-			return
-		}
+		// Serve each connection independently, so that a client can reconnect while an earlier
+		// connection remains open.
+		go serveConnection(t, nConn, config, factory, options)
+	}
+}
 
-		go ssh.DiscardRequests(reqch)
-
-		// Service the incoming Channel channel.
-		for newChannel := range chch {
-			dataChan, requests, err := newChannel.Accept()
-			assert.NoError(t, err, "Failed to accept new channel")
-
-			// Handle requests - subsystem, pty-req, shell etc.
-			go func(in <-chan *ssh.Request) {
-				for req := range in {
-					typeOk := false
-					for _, ty := range options.requestTypes {
-						if req.Type == ty {
-							typeOk = true
-							break
-						}
-					}
+func serveConnection(t assert.TestingT, nConn net.Conn, config *ssh.ServerConfig, factory HandlerFactory, options *serverOptions) {
+	_, chch, reqch, err := ssh.NewServerConn(nConn, config)
+	if err != nil {
+		return
+	}
 
-					_ = req.Reply(typeOk, nil)
+	go ssh.DiscardRequests(reqch)
+
+	// Service the incoming Channel channel.
+	for newChannel := range chch {
+		dataChan, requests, err := newChannel.Accept()
+		assert.NoError(t, err, "Failed to accept new channel")
+
+		// Handle requests - subsystem, pty-req, shell etc.
+		go func(in <-chan *ssh.Request) {
+			for req := range in {
+				typeOk := false
+				for _, ty := range options.requestTypes {
+					if req.Type == ty {
+						typeOk = true
+						break
+					}
 				}
-			}(requests)
 
-			go func() {
-				defer dataChan.Close()
-				factory(t).Handle(t, dataChan)
-			}()
-		}
+				_ = req.Reply(typeOk, nil)
+			}
+		}(requests)
+
+		go func() {
+			defer dataChan.Close()
+			factory(t).Handle(t, dataChan)
+		}()
 	}
 }
 